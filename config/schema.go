@@ -45,6 +45,28 @@ func (e UnknownColumnTypeError) Error() string {
 	return fmt.Sprintf("Unknown column type %s in column %s of schema %s", e.columnType, e.column, e.schema)
 }
 
+// UnknownColumnReferenceError indicates that a column references a filter,
+// order or path resolver, which is not known to the data source, during
+// integrity checking of a TableSchema.
+type UnknownColumnReferenceError struct {
+	schema    string
+	column    string
+	reference string
+	name      string
+}
+
+func (e UnknownColumnReferenceError) Error() string {
+	return fmt.Sprintf("Unknown %s %s in column %s of schema %s", e.reference, e.name, e.column, e.schema)
+}
+
+// ColumnReferences holds the names of the filters, orders and path resolvers,
+// which are known to a data source, and can therefore be referenced by columns.
+type ColumnReferences struct {
+	Filters       []string
+	Orders        []string
+	PathResolvers []string
+}
+
 // TableSchemaExclusion is a wrapper to describe a column path prefix that
 // is to be eliminated after a table schema was resolved.
 type TableSchemaExclusion string
@@ -53,6 +75,7 @@ type TableSchemaExclusion string
 // meta data.
 type TableSchema struct {
 	Entity     string                      `json:"entity"`
+	Backend    string                      `json:"backend"`
 	Extensions []TableSchemaExtensionTable `json:"extensions"`
 	Exclusions []TableSchemaExclusion      `json:"exclusions"`
 	Columns    []TableSchemaColumn         `json:"columns"`
@@ -62,6 +85,8 @@ var validColumnTypes = map[string]struct{}{
 	"boolean":  {},
 	"integer":  {},
 	"long":     {},
+	"float":    {},
+	"decimal":  {},
 	"string":   {},
 	"date":     {},
 	"datetime": {},
@@ -86,6 +111,47 @@ func (schema TableSchema) ValidateIntegrity(mapper EnumMapper) error {
 	return nil
 }
 
+// ValidateReferences checks that all filters, orders and path resolvers
+// referenced by the columns of the schema are known. Columns without a
+// reference fall back to the default of the data source, and are not checked.
+func (schema TableSchema) ValidateReferences(references ColumnReferences) error {
+	checks := []struct {
+		reference string
+		names     []string
+		name      func(column TableSchemaColumn) string
+	}{
+		{"filter", references.Filters, func(column TableSchemaColumn) string { return column.Filter }},
+		{"order", references.Orders, func(column TableSchemaColumn) string { return column.Order }},
+		{"path resolver", references.PathResolvers, func(column TableSchemaColumn) string { return column.PathResolver }},
+	}
+
+	for _, column := range schema.Columns {
+		for _, check := range checks {
+			name := check.name(column)
+			if name != "" && !containsString(check.names, name) {
+				return &UnknownColumnReferenceError{
+					schema:    schema.Entity,
+					column:    column.Path,
+					reference: check.reference,
+					name:      name,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ResolvedTableSchema describes the resolved schema for a table, that is
 // resolving all the extensions of a TableSchema, and assembling its columns
 // (while deleting columns applying to the exclusions).
@@ -294,6 +360,43 @@ func (schemaMapper SchemaMapper) ResolvedSchemas() map[string]ResolvedTableSchem
 	return schemas
 }
 
+// ColumnSchemas maps each column path of a resolved schema to the key of the schema, from
+// which the column is joined. Columns of the schema itself, and of extensions without a key
+// (which extend the table itself, rather than joining another one), map to the given schema.
+func (schemaMapper SchemaMapper) ColumnSchemas(schema string) (map[string]string, error) {
+	resolvedSchema, err := schemaMapper.ResolvedSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	columnSchemas := make(map[string]string, len(resolvedSchema.columns))
+	schemaMapper.collectColumnSchemas(resolvedSchema.originalSchema, schema, "", resolvedSchema, columnSchemas)
+
+	return columnSchemas, nil
+}
+
+func (schemaMapper SchemaMapper) collectColumnSchemas(tableSchema TableSchema, schema, prefix string,
+	resolvedSchema ResolvedTableSchema, columnSchemas map[string]string) {
+	for _, column := range tableSchema.Columns {
+		path := resolveColumnWithPrefix(column, prefix).Path
+
+		// Excluded columns are not part of the resolved schema
+		if _, exists := resolvedSchema.columnsMap[path]; exists {
+			columnSchemas[path] = schema
+		}
+	}
+
+	for _, extension := range tableSchema.Extensions {
+		extensionSchema := extension.Table
+		if extension.Key == "" {
+			extensionSchema = schema
+		}
+
+		schemaMapper.collectColumnSchemas(schemaMapper.schemas[extension.Table], extensionSchema,
+			extensionPrefix(tableSchema, extension, prefix), resolvedSchema, columnSchemas)
+	}
+}
+
 // ValidateIntegrity iteratively checks all schemas known to the mapper for integrity.
 // The given EnumMapper is used to check that all referenced enums exist.
 func (schemaMapper SchemaMapper) ValidateIntegrity(mapper EnumMapper) error {
@@ -306,6 +409,18 @@ func (schemaMapper SchemaMapper) ValidateIntegrity(mapper EnumMapper) error {
 	return nil
 }
 
+// ValidateReferences iteratively checks all schemas known to the mapper for
+// references to unknown filters, orders and path resolvers.
+func (schemaMapper SchemaMapper) ValidateReferences(references ColumnReferences) error {
+	for _, schema := range schemaMapper.schemas {
+		if err := schema.ValidateReferences(references); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func resolveColumns(schema TableSchema, allSchemas map[string]TableSchema) ([]TableSchemaColumn, error) {
 	newColumns, err := resolveColumnsWithPrefix(schema, allSchemas, "")
 	if err != nil {
@@ -371,19 +486,7 @@ func resolveColumnsWithPrefix(resolvableSchema TableSchema, allSchemas map[strin
 			return nil, &UnresolvableSchemaError{schema: table.Table}
 		}
 
-		extensionString := resolvableSchema.Entity
-		if prefix != "" {
-			extensionString = prefix
-		}
-
-		// Only prefix if we have a PathPrefix and/or a table key
-		if extensionString != "" && table.Key != "" {
-			extensionString += "_" + table.Key
-		} else if table.Key != "" {
-			extensionString = table.Key
-		}
-
-		resolvedColumns, err := resolveColumnsWithPrefix(targetExtensionTable, allSchemas, extensionString)
+		resolvedColumns, err := resolveColumnsWithPrefix(targetExtensionTable, allSchemas, extensionPrefix(resolvableSchema, table, prefix))
 		if err != nil {
 			return nil, err
 		}
@@ -394,6 +497,23 @@ func resolveColumnsWithPrefix(resolvableSchema TableSchema, allSchemas map[strin
 	return newColumns, nil
 }
 
+// extensionPrefix calculates the path prefix for the columns of an extension.
+func extensionPrefix(schema TableSchema, extension TableSchemaExtensionTable, prefix string) string {
+	extensionString := schema.Entity
+	if prefix != "" {
+		extensionString = prefix
+	}
+
+	// Only prefix if we have a PathPrefix and/or a table key
+	if extensionString != "" && extension.Key != "" {
+		extensionString += "_" + extension.Key
+	} else if extension.Key != "" {
+		extensionString = extension.Key
+	}
+
+	return extensionString
+}
+
 func resolveColumnWithPrefix(column TableSchemaColumn, prefix string) TableSchemaColumn {
 	var path string
 	if prefix != "" {
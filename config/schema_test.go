@@ -242,6 +242,26 @@ var _ = Describe("Schema", func() {
 			})
 		})
 
+		Context("when mapping the columns of a resolved schema to their schemas", func() {
+			It("should map columns of extensions without key to the schema itself", func() {
+				columnSchemas, err := mapper.ColumnSchemas("companies")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(columnSchemas).To(Equal(map[string]string{
+					"company_companyKey":    "companies",
+					"company_name":          "companies",
+					"company_uuid":          "companies",
+					"company_createDateUtc": "companies",
+				}))
+			})
+
+			It("should error, when trying to map an unknown schema", func() {
+				_, err := mapper.ColumnSchemas("wat")
+
+				Expect(err).To(Equal(config.ErrUnknownSchema))
+			})
+		})
+
 		Context("when trying to validate a table schema", func() {
 			var (
 				err error
@@ -255,5 +275,37 @@ var _ = Describe("Schema", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
+
+		Context("when trying to validate the references of a table schema", func() {
+			references := config.ColumnReferences{
+				Filters: []string{"StringRegExFilter", "DateTimeFilter", "NumericFilter"},
+			}
+
+			It("should not error, if all references are known", func() {
+				Expect(mapper.ValidateReferences(references)).To(Succeed())
+			})
+
+			It("should error, if a filter is unknown", func() {
+				err := mapper.ValidateReferences(config.ColumnReferences{
+					Filters: []string{"StringRegExFilter", "DateTimeFilter"},
+				})
+
+				Expect(err).To(BeAssignableToTypeOf(&config.UnknownColumnReferenceError{}))
+				Expect(err.Error()).To(Equal("Unknown filter NumericFilter in column company_companyKey of schema company"))
+			})
+		})
+
+		Context("when trying to validate a file which contains decimal and float columns", func() {
+			BeforeEach(func() {
+				mapper, err = config.NewSchemaMapperFromFolder(filepath.Join("testfiles", "schema-numeric-types"))
+				Expect(err).ToNot(HaveOccurred())
+
+				err = mapper.ValidateIntegrity(config.EnumMapper{})
+			})
+
+			It("should not error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
 	})
 })
@@ -40,4 +40,66 @@ const (
 
 	// FilterNotEquals indicates that the column must NOT match the exact filter value.
 	FilterNotEquals FilterMode = "NOT_EQUALS"
+
+	// FilterIn indicates that the column must match one of the filter values, which are given
+	// as a list.
+	FilterIn FilterMode = "IN"
+
+	// FilterBetween indicates that the column must lie within the inclusive range of the filter
+	// values, which are given as a list of exactly two values.
+	FilterBetween FilterMode = "BETWEEN"
+
+	// FilterIsNull indicates that the column must be null. The filter value is ignored.
+	FilterIsNull FilterMode = "IS_NULL"
+
+	// FilterIsNotNull indicates that the column must NOT be null. The filter value is ignored.
+	FilterIsNotNull FilterMode = "IS_NOT_NULL"
+
+	// FilterContains indicates that the column must contain the filter value.
+	FilterContains FilterMode = "CONTAINS"
+
+	// FilterStartsWith indicates that the column must start with the filter value.
+	FilterStartsWith FilterMode = "STARTS_WITH"
+
+	// FilterEndsWith indicates that the column must end with the filter value.
+	FilterEndsWith FilterMode = "ENDS_WITH"
+)
+
+// FilterModes returns all known filter modes.
+func FilterModes() []FilterMode {
+	return []FilterMode{
+		FilterEquals, FilterNotEquals, FilterGreater, FilterGreaterEquals, FilterLesser, FilterLesserEquals,
+		FilterIn, FilterBetween, FilterIsNull, FilterIsNotNull, FilterContains, FilterStartsWith, FilterEndsWith,
+	}
+}
+
+// Aggregate is an abstract definition of a function to aggregate a column by.
+type Aggregate string
+
+const (
+	// AggregateCount counts the rows, or the non-null values of a column.
+	AggregateCount Aggregate = "COUNT"
+
+	// AggregateSum sums up the values of a numeric column.
+	AggregateSum Aggregate = "SUM"
+
+	// AggregateAvg averages the values of a numeric column.
+	AggregateAvg Aggregate = "AVG"
+
+	// AggregateMin selects the smallest value of an orderable column.
+	AggregateMin Aggregate = "MIN"
+
+	// AggregateMax selects the largest value of an orderable column.
+	AggregateMax Aggregate = "MAX"
+)
+
+// FacetOrder describes by what the values of a facet are ordered.
+type FacetOrder string
+
+const (
+	// FacetOrderCount orders facet values by the amount of rows having the value.
+	FacetOrderCount FacetOrder = "COUNT"
+
+	// FacetOrderValue orders facet values by the value itself.
+	FacetOrderValue FacetOrder = "VALUE"
 )
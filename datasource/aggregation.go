@@ -0,0 +1,251 @@
+package datasource
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tableaux-project/tableaux"
+)
+
+// Aggregator is implemented by Connectors which can group and aggregate data, instead of
+// only returning flat rows. Callers are expected to check for the interface on a Connector.
+type Aggregator interface {
+	// ValidateAggregation validates if the implementation is able to serve the aggregation
+	// request, that is all paths are known, and all aggregates fit the type of their column.
+	ValidateAggregation(ctx context.Context, request AggregationRequest) error
+
+	// FetchAggregation groups all rows matching the request by the grouped paths, and
+	// aggregates each group.
+	FetchAggregation(ctx context.Context, request AggregationRequest) (*AggregationResult, error)
+}
+
+// AggregationResult is the result of an aggregation request, with one row per group.
+type AggregationResult []AggregationRow
+
+// AggregationRow is a single group of an aggregation request.
+type AggregationRow struct {
+	// Groups maps the grouped paths to the type-safe values of the group.
+	Groups map[string]interface{}
+
+	// Values maps the alias of each Aggregation to its aggregated value. COUNT is always
+	// returned as uint64, AVG as float64, SUM as int64 for integral columns (float64
+	// otherwise), while MIN and MAX retain the type of their column. SUM and AVG of decimal
+	// columns are returned as the string of the decimal number, so no precision is lost.
+	// Aggregates over an empty set of values are nil.
+	Values map[string]interface{}
+}
+
+// Aggregation designates a path to be aggregated by a function.
+type Aggregation struct {
+	function tableaux.Aggregate
+	path     string
+}
+
+// NewAggregation constructs a new Aggregation. For tableaux.AggregateCount, the path
+// might be empty, which counts the rows of each group.
+func NewAggregation(function tableaux.Aggregate, path string) Aggregation {
+	return Aggregation{
+		function: function,
+		path:     path,
+	}
+}
+
+// Function returns the function to aggregate the path by.
+func (a Aggregation) Function() tableaux.Aggregate {
+	return a.function
+}
+
+// Path returns the path which is to be aggregated. The path is empty for counting rows.
+func (a Aggregation) Path() string {
+	return a.path
+}
+
+// Alias returns the key of the Aggregation in AggregationRow.Values, e.g. "sum_person_salary",
+// or just "count" for counting rows.
+func (a Aggregation) Alias() string {
+	if a.path == "" {
+		return strings.ToLower(string(a.function))
+	}
+
+	return strings.ToLower(string(a.function)) + "_" + a.path
+}
+
+// The builtin column types, which can be summed up and averaged.
+var numericColumnTypes = map[string]struct{}{
+	"integer": {},
+	"long":    {},
+	"float":   {},
+	"decimal": {},
+}
+
+// The builtin column types, which have a natural order. Enums and booleans are not ordered.
+var orderedColumnTypes = map[string]struct{}{
+	"integer":  {},
+	"long":     {},
+	"float":    {},
+	"decimal":  {},
+	"string":   {},
+	"date":     {},
+	"datetime": {},
+}
+
+// AggregationSupportsType checks if the aggregate function can be applied to a column of
+// the given type. COUNT is applicable to any column, SUM and AVG only to numeric columns,
+// and MIN and MAX to all columns with a natural order.
+func AggregationSupportsType(function tableaux.Aggregate, columnType string) bool {
+	columnType = strings.ToLower(columnType)
+
+	switch function {
+	case tableaux.AggregateCount:
+		return true
+	case tableaux.AggregateSum, tableaux.AggregateAvg:
+		_, isNumeric := numericColumnTypes[columnType]
+		return isNumeric
+	case tableaux.AggregateMin, tableaux.AggregateMax:
+		_, isOrdered := orderedColumnTypes[columnType]
+		return isOrdered
+	default:
+		return false
+	}
+}
+
+// IsIntegralColumnType checks if the column type holds whole numbers only.
+func IsIntegralColumnType(columnType string) bool {
+	columnType = strings.ToLower(columnType)
+	return columnType == "integer" || columnType == "long"
+}
+
+// AggregationRequest is the description of an aggregation request. Like a Request, it
+// references the schema by its key, and columns by their paths.
+type AggregationRequest struct {
+	schema       string
+	groupBy      []string
+	aggregations []Aggregation
+	filters      []FilterGroup
+	where        FilterExpression
+	orders       []Order
+	limit        uint64
+	locale       string
+	timeZone     string
+}
+
+// Schema returns the key of the schema, under which it is known to the config.SchemaMapper.
+func (r AggregationRequest) Schema() string {
+	return r.schema
+}
+
+// GroupBy returns the paths to group by. Without any, all rows form a single group.
+func (r AggregationRequest) GroupBy() []string {
+	return r.groupBy
+}
+
+// Aggregations returns all aggregations, which are applied to each group.
+func (r AggregationRequest) Aggregations() []Aggregation {
+	return r.aggregations
+}
+
+// Filters returns all FilterGroups, which are to be AND'ed.
+func (r AggregationRequest) Filters() []FilterGroup {
+	return r.filters
+}
+
+// Where returns the additional FilterExpression, which is AND'ed with the FilterGroups.
+// Where might be nil.
+func (r AggregationRequest) Where() FilterExpression {
+	return r.where
+}
+
+// FilterExpression returns all filters of the request as a single expression, or nil if
+// the request has no filters at all.
+func (r AggregationRequest) FilterExpression() FilterExpression {
+	return combineFilters(r.filters, r.where)
+}
+
+// Orders returns all orders, in order of precedence. An order either refers to a grouped
+// path, or to the alias of an Aggregation.
+func (r AggregationRequest) Orders() []Order {
+	return r.orders
+}
+
+// Limit returns the maximum amount of groups to be fetched, or 0 for no limit.
+func (r AggregationRequest) Limit() uint64 {
+	return r.limit
+}
+
+// Locale returns the locale which is used for locale dependent operations.
+func (r AggregationRequest) Locale() string {
+	return r.locale
+}
+
+// TimeZone returns the name of the time zone, in which date and time filter values without an
+// explicit offset are interpreted. An empty name stands for UTC.
+func (r AggregationRequest) TimeZone() string {
+	return r.timeZone
+}
+
+// AggregationRequestBuilder assembles an AggregationRequest step by step.
+type AggregationRequestBuilder struct {
+	request AggregationRequest
+}
+
+// NewAggregationRequestBuilder creates a new AggregationRequestBuilder for the schema with the given key.
+func NewAggregationRequestBuilder(schema string) *AggregationRequestBuilder {
+	return &AggregationRequestBuilder{
+		request: AggregationRequest{
+			schema: schema,
+		},
+	}
+}
+
+// GroupBy appends paths to group by to the request.
+func (b *AggregationRequestBuilder) GroupBy(paths ...string) *AggregationRequestBuilder {
+	b.request.groupBy = append(b.request.groupBy, paths...)
+	return b
+}
+
+// Aggregations appends Aggregations to the request.
+func (b *AggregationRequestBuilder) Aggregations(aggregations ...Aggregation) *AggregationRequestBuilder {
+	b.request.aggregations = append(b.request.aggregations, aggregations...)
+	return b
+}
+
+// Filters appends FilterGroups to the request.
+func (b *AggregationRequestBuilder) Filters(filters ...FilterGroup) *AggregationRequestBuilder {
+	b.request.filters = append(b.request.filters, filters...)
+	return b
+}
+
+// Where sets a FilterExpression, which is AND'ed with the FilterGroups of the request.
+func (b *AggregationRequestBuilder) Where(where FilterExpression) *AggregationRequestBuilder {
+	b.request.where = where
+	return b
+}
+
+// Orders appends Orders to the request.
+func (b *AggregationRequestBuilder) Orders(orders ...Order) *AggregationRequestBuilder {
+	b.request.orders = append(b.request.orders, orders...)
+	return b
+}
+
+// Limit sets the maximum amount of groups to be fetched.
+func (b *AggregationRequestBuilder) Limit(limit uint64) *AggregationRequestBuilder {
+	b.request.limit = limit
+	return b
+}
+
+// Locale sets the locale of the request.
+func (b *AggregationRequestBuilder) Locale(locale string) *AggregationRequestBuilder {
+	b.request.locale = locale
+	return b
+}
+
+// TimeZone sets the name of the time zone of the request, e.g. "Europe/Berlin".
+func (b *AggregationRequestBuilder) TimeZone(timeZone string) *AggregationRequestBuilder {
+	b.request.timeZone = timeZone
+	return b
+}
+
+// Build returns the assembled AggregationRequest.
+func (b *AggregationRequestBuilder) Build() AggregationRequest {
+	return b.request
+}
@@ -0,0 +1,50 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/tableaux-project/tableaux"
+)
+
+func TestAggregationAlias(t *testing.T) {
+	tables := []struct {
+		aggregation Aggregation
+		alias       string
+	}{
+		{NewAggregation(tableaux.AggregateCount, ""), "count"},
+		{NewAggregation(tableaux.AggregateCount, "person_name"), "count_person_name"},
+		{NewAggregation(tableaux.AggregateSum, "person_salary"), "sum_person_salary"},
+	}
+
+	for _, table := range tables {
+		if alias := table.aggregation.Alias(); alias != table.alias {
+			t.Errorf("Alias of %+v was incorrect, got: %s, want: %s.", table.aggregation, alias, table.alias)
+		}
+	}
+}
+
+func TestAggregationSupportsType(t *testing.T) {
+	tables := []struct {
+		function   tableaux.Aggregate
+		columnType string
+		supported  bool
+	}{
+		{tableaux.AggregateCount, "Boolean", true},
+		{tableaux.AggregateCount, "address.Type", true},
+		{tableaux.AggregateSum, "Integer", true},
+		{tableaux.AggregateSum, "String", false},
+		{tableaux.AggregateAvg, "Long", true},
+		{tableaux.AggregateAvg, "Date", false},
+		{tableaux.AggregateMin, "Date", true},
+		{tableaux.AggregateMax, "Boolean", false},
+		{tableaux.AggregateMax, "address.Type", false},
+		{tableaux.Aggregate("MEDIAN"), "Integer", false},
+	}
+
+	for _, table := range tables {
+		if supported := AggregationSupportsType(table.function, table.columnType); supported != table.supported {
+			t.Errorf("AggregationSupportsType(%s, %s) was incorrect, got: %t, want: %t.",
+				table.function, table.columnType, supported, table.supported)
+		}
+	}
+}
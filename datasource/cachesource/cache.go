@@ -0,0 +1,129 @@
+package cachesource
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// A single cached value, with the schema and entities it depends on.
+type cacheEntry struct {
+	key      string
+	schema   string
+	entities map[string]struct{}
+	value    interface{}
+	expires  time.Time
+}
+
+// A size bound least-recently-used cache, whose entries expire after a TTL.
+type cache struct {
+	mutex      sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	recency    *list.List
+	now        func() time.Time
+
+	// Counts the removals, so values fetched before a removal are not cached afterwards
+	generation uint64
+}
+
+func newCache(ttl time.Duration, maxEntries int) *cache {
+	return &cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		recency:    list.New(),
+		now:        time.Now,
+	}
+}
+
+// Returns the value cached under the key, if there is one which is not expired.
+func (c *cache) get(key string) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if c.ttl > 0 && !c.now().Before(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.recency.MoveToFront(element)
+	return entry.value, true
+}
+
+// Returns the current generation, which is to be passed to put for a value fetched afterwards.
+func (c *cache) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
+// Caches a value under the key, evicting the least recently used entries if the cache is full.
+// If entries were removed since the given generation, the value might be stale, and is not
+// cached.
+func (c *cache) put(generation uint64, key, schema string, entities map[string]struct{}, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if element, exists := c.entries[key]; exists {
+		c.remove(element)
+	}
+
+	c.entries[key] = c.recency.PushFront(&cacheEntry{
+		key:      key,
+		schema:   schema,
+		entities: entities,
+		value:    value,
+		expires:  c.now().Add(c.ttl),
+	})
+
+	for c.maxEntries > 0 && c.recency.Len() > c.maxEntries {
+		c.remove(c.recency.Back())
+	}
+}
+
+// Removes all entries, for which the given function returns true.
+func (c *cache) removeIf(matches func(entry *cacheEntry) bool) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+
+	removed := 0
+	for element := c.recency.Front(); element != nil; {
+		next := element.Next()
+		if matches(element.Value.(*cacheEntry)) {
+			c.remove(element)
+			removed++
+		}
+
+		element = next
+	}
+
+	return removed
+}
+
+// Returns the number of entries, including expired ones which have not been removed yet.
+func (c *cache) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.recency.Len()
+}
+
+func (c *cache) remove(element *list.Element) {
+	c.recency.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+}
@@ -0,0 +1,291 @@
+// Package cachesource implements a caching decorator for data sources. Results, counts, facets
+// and aggregations are cached by a normalized form of their request, for a limited time.
+package cachesource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gopkg.in/birkirb/loggers.v1/log"
+
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+)
+
+// A cached FetchData call.
+type dataEntry struct {
+	result        datasource.Result
+	totalCount    uint64
+	filteredCount uint64
+	continuation  string
+}
+
+// Connector is a datasource.Connector, which caches the results of another Connector. Requests
+// are only validated by the decorated Connector, and FetchRows is never cached, as its rows are
+// streamed. Results fetched while entries are invalidated are not cached, as they might be
+// stale. Requests with relative date values (e.g. "today") are cached per day, as the dates
+// they denote change.
+type Connector struct {
+	connector    datasource.Connector
+	schemaMapper config.SchemaMapper
+	cache        *cache
+}
+
+// NewConnector creates a new caching Connector, which decorates the given Connector. Entries
+// expire after the ttl, and at most maxEntries entries are kept, evicting the least recently
+// used entries first. A ttl or maxEntries of zero disables the respective bound.
+func NewConnector(connector datasource.Connector, schemaMapper config.SchemaMapper, ttl time.Duration, maxEntries int) *Connector {
+	return &Connector{
+		connector:    connector,
+		schemaMapper: schemaMapper,
+		cache:        newCache(ttl, maxEntries),
+	}
+}
+
+func (th Connector) ValidateRequest(request datasource.Request) error {
+	return th.connector.ValidateRequest(request)
+}
+
+func (th Connector) ValidateRequestContext(ctx context.Context, request datasource.Request) error {
+	return th.connector.ValidateRequestContext(ctx, request)
+}
+
+func (th Connector) FetchData(request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	return th.FetchDataContext(context.Background(), request)
+}
+
+func (th Connector) FetchDataContext(ctx context.Context, request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, 0, "", err
+	}
+
+	key := th.key(request.Key(), request.FilterExpression(), request.TimeZone())
+	generation := th.cache.currentGeneration()
+	if cached, exists := th.cache.get(key); exists {
+		entry := cached.(dataEntry)
+		log.WithField("schema", request.Schema()).Debug("Serving data from cache")
+
+		result := entry.result.Copy()
+		return &result, entry.totalCount, entry.filteredCount, entry.continuation, nil
+	}
+
+	result, totalCount, filteredCount, continuation, err := th.connector.FetchDataContext(ctx, request)
+	if err != nil {
+		return nil, 0, 0, "", err
+	}
+
+	th.cache.put(generation, key, request.Schema(), th.entities(request.Schema()), dataEntry{
+		result:        result.Copy(),
+		totalCount:    totalCount,
+		filteredCount: filteredCount,
+		continuation:  continuation,
+	})
+
+	return result, totalCount, filteredCount, continuation, nil
+}
+
+func (th Connector) FetchRows(ctx context.Context, request datasource.Request) (datasource.Rows, error) {
+	return th.connector.FetchRows(ctx, request)
+}
+
+func (th Connector) FetchFacets(ctx context.Context, request datasource.FacetRequest) (*datasource.FacetResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	key := th.key(request.Key(), request.Request().FilterExpression(), request.Request().TimeZone())
+	generation := th.cache.currentGeneration()
+	if cached, exists := th.cache.get(key); exists {
+		facetResult := append(datasource.FacetResult{}, cached.(datasource.FacetResult)...)
+		return &facetResult, nil
+	}
+
+	facetResult, err := th.connector.FetchFacets(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := request.Request().Schema()
+	th.cache.put(generation, key, schema, th.entities(schema), append(datasource.FacetResult{}, *facetResult...))
+
+	return facetResult, nil
+}
+
+// ValidateAggregation validates the request against the decorated Connector, which must
+// implement datasource.Aggregator.
+func (th Connector) ValidateAggregation(ctx context.Context, request datasource.AggregationRequest) error {
+	aggregator, err := th.aggregator()
+	if err != nil {
+		return err
+	}
+
+	return aggregator.ValidateAggregation(ctx, request)
+}
+
+// FetchAggregation fetches the aggregation from the decorated Connector, which must implement
+// datasource.Aggregator, and caches it.
+func (th Connector) FetchAggregation(ctx context.Context, request datasource.AggregationRequest) (*datasource.AggregationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	aggregator, err := th.aggregator()
+	if err != nil {
+		return nil, err
+	}
+
+	key := th.key(request.Key(), request.FilterExpression(), request.TimeZone())
+	generation := th.cache.currentGeneration()
+	if cached, exists := th.cache.get(key); exists {
+		aggregationResult := copyAggregationResult(cached.(datasource.AggregationResult))
+		return &aggregationResult, nil
+	}
+
+	aggregationResult, err := aggregator.FetchAggregation(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	th.cache.put(generation, key, request.Schema(), th.entities(request.Schema()), copyAggregationResult(*aggregationResult))
+
+	return aggregationResult, nil
+}
+
+// InvalidateSchema removes all cached entries of requests against the given schema, and
+// returns the number of removed entries.
+func (th Connector) InvalidateSchema(schema string) int {
+	removed := th.cache.removeIf(func(entry *cacheEntry) bool {
+		return entry.schema == schema
+	})
+
+	log.WithFields("schema", schema, "removed", removed).Debug("Invalidated cached schema")
+	return removed
+}
+
+// InvalidateEntity removes all cached entries of requests, which depend on the given entity.
+// That is, requests against schemas of the entity, or of schemas extended by the entity. It
+// returns the number of removed entries.
+func (th Connector) InvalidateEntity(entity string) int {
+	removed := th.cache.removeIf(func(entry *cacheEntry) bool {
+		_, dependent := entry.entities[entity]
+		return dependent
+	})
+
+	log.WithFields("entity", entity, "removed", removed).Debug("Invalidated cached entity")
+	return removed
+}
+
+// InvalidateAll removes all cached entries, and returns the number of removed entries.
+func (th Connector) InvalidateAll() int {
+	return th.cache.removeIf(func(entry *cacheEntry) bool {
+		return true
+	})
+}
+
+func (th Connector) aggregator() (datasource.Aggregator, error) {
+	aggregator, isAggregator := th.connector.(datasource.Aggregator)
+	if !isAggregator {
+		return nil, fmt.Errorf("connector %T does not support aggregations", th.connector)
+	}
+
+	return aggregator, nil
+}
+
+// Returns the key to cache a request under. If any filter value is a relative date expression,
+// the current day in the time zone of the request is added to the key, so the cached result is
+// not served anymore once the expression denotes different dates. Unknown time zones fall back
+// to UTC, as the request is rejected by the decorated Connector anyway.
+func (th Connector) key(requestKey string, filterExpression datasource.FilterExpression, timeZone string) string {
+	if !hasRelativeValue(filterExpression) {
+		return requestKey
+	}
+
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	return requestKey + "@" + th.cache.now().In(location).Format("2006-01-02")
+}
+
+// Reports, whether any filter value of the expression is a relative date expression.
+func hasRelativeValue(filterExpression datasource.FilterExpression) bool {
+	for _, filterGroup := range datasource.FilterGroupsOf(filterExpression) {
+		for _, groupFilter := range filterGroup.Filters() {
+			if isRelativeValue(groupFilter.Value()) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Reports, whether the value, or any value of a list (e.g. the bounds of BETWEEN), is a
+// relative date expression.
+func isRelativeValue(value interface{}) bool {
+	switch typedValue := value.(type) {
+	case string:
+		_, _, relative, _ := dates.RelativeRange(typedValue, dates.NewEnvironment(time.UTC, "", nil))
+		return relative
+	case []interface{}:
+		for _, element := range typedValue {
+			if isRelativeValue(element) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Collects the entities, which the data of a schema depends on. That is, the entity of the
+// schema, and recursively the entities of its extensions.
+func (th Connector) entities(schema string) map[string]struct{} {
+	entities := make(map[string]struct{})
+	th.collectEntities(schema, make(map[string]struct{}), entities)
+
+	return entities
+}
+
+func (th Connector) collectEntities(schema string, visited, entities map[string]struct{}) {
+	if _, isVisited := visited[schema]; isVisited {
+		return
+	}
+
+	visited[schema] = struct{}{}
+
+	tableSchema, err := th.schemaMapper.Schema(schema)
+	if err != nil {
+		return
+	}
+
+	entities[tableSchema.Entity] = struct{}{}
+	for _, extension := range tableSchema.Extensions {
+		th.collectEntities(extension.Table, visited, entities)
+	}
+}
+
+// Copies an aggregation result, so cached results cannot be modified by callers.
+func copyAggregationResult(aggregationResult datasource.AggregationResult) datasource.AggregationResult {
+	copied := make(datasource.AggregationResult, len(aggregationResult))
+	for i, row := range aggregationResult {
+		copied[i] = datasource.AggregationRow{
+			Groups: copyRow(row.Groups),
+			Values: copyRow(row.Values),
+		}
+	}
+
+	return copied
+}
+
+func copyRow(row map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(row))
+	for key, value := range row {
+		copied[key] = value
+	}
+
+	return copied
+}
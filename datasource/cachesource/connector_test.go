@@ -0,0 +1,270 @@
+package cachesource
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/memsource"
+)
+
+// Counts the data fetches of the decorated Connector.
+type countingConnector struct {
+	datasource.Connector
+	fetches int
+}
+
+func (c *countingConnector) FetchDataContext(ctx context.Context, request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	c.fetches++
+	return c.Connector.FetchDataContext(ctx, request)
+}
+
+func newTestConnector(t *testing.T, ttl time.Duration, maxEntries int) (*Connector, *countingConnector) {
+	enumMapper, err := config.NewEnumMapperFromFolder(filepath.Join("testfiles", "enum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translator, err := config.NewTranslatorFromFolder(filepath.Join("testfiles", "i18n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaMapper, err := config.NewSchemaMapperFromFolder(filepath.Join("testfiles", "schema"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connector, err := memsource.NewConnector(map[string]memsource.Table{
+		"persons": {
+			{"person_id": int64(1), "person_name": "Alice", "person_organization_name": "Acme"},
+			{"person_id": int64(2), "person_name": "Bob"},
+		},
+		"organizations": {
+			{"organization_id": int64(1), "organization_name": "Acme"},
+		},
+	}, enumMapper, translator, schemaMapper)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counting := &countingConnector{Connector: connector}
+	return NewConnector(counting, schemaMapper, ttl, maxEntries), counting
+}
+
+func fetch(t *testing.T, connector *Connector, request datasource.Request) datasource.Result {
+	result, _, _, _, err := connector.FetchData(request)
+	if err != nil {
+		t.Fatalf("FetchData failed: %s", err)
+	}
+
+	return *result
+}
+
+func TestFetchDataCaching(t *testing.T) {
+	connector, counting := newTestConnector(t, time.Minute, 0)
+
+	first := datasource.NewRequestBuilder("persons").
+		Columns("person_id", "person_name").
+		Filters(
+			datasource.NewSimpleFilterGroup("person_id", tableaux.FilterEquals, []interface{}{int64(1), int64(2)}),
+			datasource.NewSimpleFilterGroup("person_name", tableaux.FilterNotEquals, []interface{}{"Carol"}),
+		).
+		Locale("en").
+		Build()
+
+	// Equal apart from the order of the columns, filter groups and filters
+	second := datasource.NewRequestBuilder("persons").
+		Columns("person_name", "person_id").
+		Filters(
+			datasource.NewSimpleFilterGroup("person_name", tableaux.FilterNotEquals, []interface{}{"Carol"}),
+			datasource.NewSimpleFilterGroup("person_id", tableaux.FilterEquals, []interface{}{int64(2), int64(1)}),
+		).
+		Locale("en").
+		Build()
+
+	fetch(t, connector, first)[0]["person_name"] = "Mallory"
+
+	if result := fetch(t, connector, second); result[0]["person_name"] != "Alice" {
+		t.Errorf("Cached result was modified, got: %v, want: Alice.", result[0]["person_name"])
+	}
+
+	if counting.fetches != 1 {
+		t.Errorf("Fetches were incorrect, got: %d, want: 1.", counting.fetches)
+	}
+
+	fetch(t, connector, datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").Limit(1).Build())
+
+	if counting.fetches != 2 {
+		t.Errorf("Fetches for a different request were incorrect, got: %d, want: 2.", counting.fetches)
+	}
+}
+
+func TestFetchDataExpiration(t *testing.T) {
+	connector, counting := newTestConnector(t, time.Minute, 2)
+
+	now := time.Now()
+	connector.cache.now = func() time.Time {
+		return now
+	}
+
+	requests := []datasource.Request{
+		datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").Build(),
+		datasource.NewRequestBuilder("persons").Columns("person_name").Locale("en").Build(),
+		datasource.NewRequestBuilder("organizations").Columns("organization_name").Locale("en").Build(),
+	}
+
+	for _, request := range requests {
+		fetch(t, connector, request)
+	}
+
+	if connector.cache.len() != 2 {
+		t.Errorf("Size bound was not applied, got: %d entries, want: 2.", connector.cache.len())
+	}
+
+	// The least recently used entry was evicted
+	fetch(t, connector, requests[0])
+	if counting.fetches != 4 {
+		t.Errorf("Fetches after eviction were incorrect, got: %d, want: 4.", counting.fetches)
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	fetch(t, connector, requests[0])
+	if counting.fetches != 5 {
+		t.Errorf("Fetches after expiration were incorrect, got: %d, want: 5.", counting.fetches)
+	}
+}
+
+func TestFetchDataRelativeDates(t *testing.T) {
+	connector, counting := newTestConnector(t, 0, 0)
+
+	// Already the next day in Berlin
+	now := time.Date(2024, time.March, 1, 23, 30, 0, 0, time.UTC)
+	connector.cache.now = func() time.Time {
+		return now
+	}
+
+	relative := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(datasource.NewSimpleFilterGroup("person_name", tableaux.FilterNotEquals, []interface{}{"Last 7 Days"})).
+		Locale("en").
+		TimeZone("Europe/Berlin").
+		Build()
+
+	absolute := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(datasource.NewSimpleFilterGroup("person_name", tableaux.FilterNotEquals, []interface{}{"2024-03-01"})).
+		Locale("en").
+		TimeZone("Europe/Berlin").
+		Build()
+
+	fetch(t, connector, relative)
+	fetch(t, connector, absolute)
+
+	now = now.Add(time.Hour)
+
+	fetch(t, connector, relative)
+	fetch(t, connector, absolute)
+
+	if counting.fetches != 2 {
+		t.Errorf("Fetches on the same day were incorrect, got: %d, want: 2.", counting.fetches)
+	}
+
+	now = now.Add(24 * time.Hour)
+
+	fetch(t, connector, relative)
+	fetch(t, connector, absolute)
+
+	if counting.fetches != 3 {
+		t.Errorf("Fetches on the next day were incorrect, got: %d, want: 3.", counting.fetches)
+	}
+}
+
+// Blocks the data fetches of the decorated Connector, until they are released.
+type blockingConnector struct {
+	datasource.Connector
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c blockingConnector) FetchDataContext(ctx context.Context, request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	c.started <- struct{}{}
+	<-c.release
+
+	return c.Connector.FetchDataContext(ctx, request)
+}
+
+func TestFetchDataInvalidatedWhileFetching(t *testing.T) {
+	cached, counting := newTestConnector(t, 0, 0)
+
+	blocking := blockingConnector{
+		Connector: counting,
+		started:   make(chan struct{}),
+		release:   make(chan struct{}),
+	}
+	connector := NewConnector(blocking, cached.schemaMapper, 0, 0)
+
+	request := datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").Build()
+
+	fetched := make(chan struct{})
+	go func() {
+		defer close(fetched)
+		fetch(t, connector, request)
+	}()
+
+	// The data was fetched before the invalidation, so it must not be cached afterwards
+	<-blocking.started
+	connector.InvalidateEntity("person")
+	close(blocking.release)
+	<-fetched
+
+	if connector.cache.len() != 0 {
+		t.Errorf("Size after invalidation while fetching was incorrect, got: %d entries, want: 0.", connector.cache.len())
+	}
+
+	go func() {
+		<-blocking.started
+	}()
+
+	fetch(t, connector, request)
+	fetch(t, connector, request)
+
+	if counting.fetches != 2 {
+		t.Errorf("Fetches after invalidation were incorrect, got: %d, want: 2.", counting.fetches)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	connector, _ := newTestConnector(t, 0, 0)
+
+	persons := datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").Build()
+	organizations := datasource.NewRequestBuilder("organizations").Columns("organization_name").Locale("en").Build()
+
+	tables := []struct {
+		name       string
+		invalidate func() int
+		removed    int
+	}{
+		{"schema", func() int { return connector.InvalidateSchema("persons") }, 1},
+		{"extended entity", func() int { return connector.InvalidateEntity("organization") }, 2},
+		{"own entity", func() int { return connector.InvalidateEntity("person") }, 1},
+		{"unknown entity", func() int { return connector.InvalidateEntity("unknown") }, 0},
+		{"all", connector.InvalidateAll, 2},
+	}
+
+	for _, table := range tables {
+		fetch(t, connector, persons)
+		fetch(t, connector, organizations)
+
+		if removed := table.invalidate(); removed != table.removed {
+			t.Errorf("Invalidating %s was incorrect, got: %d removed, want: %d.", table.name, removed, table.removed)
+		}
+
+		connector.InvalidateAll()
+	}
+}
@@ -3,28 +3,62 @@
 package datasource
 
 import (
+	"context"
+
 	"github.com/tableaux-project/tableaux"
-	"github.com/tableaux-project/tableaux/config"
 )
 
 // Connector defines the central contract between tableaux and an implementing data source.
 type Connector interface {
 	// ValidateRequest validates if the implementation is able to serve the request. Any error
 	// indicates that execution of FetchData will probably fail, and is not expected to work.
-	// This methods primary use case is to validate user-made requests for errors.
-	ValidateRequest(columns []config.TableSchemaColumn, schema config.ResolvedTableSchema, filters []FilterGroup,
-		orders []Order, globalSearch string, limit, offset uint64, locale string) error
-
-	// FetchData is the entry point for retrieving data from a data source.
-	FetchData(columns []config.TableSchemaColumn, schema config.ResolvedTableSchema, filters []FilterGroup,
-		orders []Order, globalSearch string, limit, offset uint64, locale string) (result *Result,
-		totalCount uint64, filteredCount uint64, error error)
+	// This methods primary use case is to validate user-made requests for errors. Problems of
+	// the request itself are returned as ValidationErrors, so they can be reported per field.
+	ValidateRequest(request Request) error
+
+	// ValidateRequestContext is the context-aware variant of ValidateRequest.
+	ValidateRequestContext(ctx context.Context, request Request) error
+
+	// FetchData is the entry point for retrieving data from a data source. If the request is
+	// limited, and more data is available, an opaque continuation token is returned, which
+	// can be used to fetch the next page via Request.Continuation.
+	FetchData(request Request) (result *Result, totalCount uint64, filteredCount uint64,
+		continuation string, error error)
+
+	// FetchDataContext is the context-aware variant of FetchData. Cancellation and deadlines of
+	// the context must be honoured by all operations against the underlying data source. If the
+	// context is done before all data has been retrieved, the context error is returned.
+	FetchDataContext(ctx context.Context, request Request) (result *Result, totalCount uint64,
+		filteredCount uint64, continuation string, error error)
+
+	// FetchRows is the streaming variant of FetchDataContext. Instead of a Result, it returns
+	// Rows, which must be closed by the caller once done.
+	FetchRows(ctx context.Context, request Request) (Rows, error)
+
+	// FetchFacets returns the distinct values of a single path, and how many rows have each
+	// value, under the filters and the global search of the request. This is primarily used
+	// to populate filter dropdowns.
+	FetchFacets(ctx context.Context, request FacetRequest) (*FacetResult, error)
 }
 
 // Result is the abstract data retrieval result of a data source implementation.
 // A Result is mapping the fetched paths to their type-safe implementations
 type Result []map[string]interface{}
 
+// Copy returns a copy of the Result, whose rows can be modified without affecting the original.
+// The values themselves are not copied.
+func (r Result) Copy() Result {
+	copied := make(Result, len(r))
+	for i, row := range r {
+		copied[i] = make(map[string]interface{}, len(row))
+		for path, value := range row {
+			copied[i][path] = value
+		}
+	}
+
+	return copied
+}
+
 // FilterGroup designates a path to be filtered by one or multiple actual Filters.
 // A FilterGroup acts as an OR-chain. That is, all Filters contained in a single FilterGroup
 // must be "OR'd" to each other. On the other hand, if multiple FilterGroups for one path
@@ -0,0 +1,93 @@
+package dates
+
+import (
+	"fmt"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Layouts of date times without an explicit offset, which are interpreted in the location of the
+// environment. Fractional seconds are accepted, even though the layouts lack them.
+var localDateTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// DateRange resolves a date value into the half-open range of days [from, to) it denotes, with
+// both being midnight in the location of the environment. Values are ISO-8601 dates, date times
+// which are reduced to their date in the location, or relative dates as of RelativeRange.
+func DateRange(value interface{}, environment Environment) (time.Time, time.Time, error) {
+	if from, to, isRelative, err := relativeRange(value, environment); isRelative {
+		return from, to, err
+	}
+
+	parsed, _, err := parseTime(value, environment.Location)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	day := parsed.In(environment.Location)
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, environment.Location)
+
+	return from, from.AddDate(0, 0, 1), nil
+}
+
+// DateTimeRange resolves a date time value into the half-open range [from, to) it denotes. ISO-8601
+// date times denote their whole second, while dates and relative dates as of RelativeRange denote
+// their whole days in the location of the environment.
+func DateTimeRange(value interface{}, environment Environment) (time.Time, time.Time, error) {
+	if from, to, isRelative, err := relativeRange(value, environment); isRelative {
+		return from, to, err
+	}
+
+	parsed, dateOnly, err := parseTime(value, environment.Location)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	from := parsed.Truncate(time.Second)
+	if dateOnly {
+		// Days are not always 24 hours long, so the next day is derived from the calendar
+		return from, from.AddDate(0, 0, 1), nil
+	}
+
+	return from, from.Add(time.Second), nil
+}
+
+// Resolves the value as relative date, if it is a string.
+func relativeRange(value interface{}, environment Environment) (time.Time, time.Time, bool, error) {
+	expression, isString := value.(string)
+	if !isString {
+		return time.Time{}, time.Time{}, false, nil
+	}
+
+	return RelativeRange(expression, environment)
+}
+
+// Parses a time.Time or an ISO-8601 string. Values without an explicit offset are interpreted in
+// the given location. The returned bool reports, whether the value only denotes a date.
+func parseTime(value interface{}, location *time.Location) (time.Time, bool, error) {
+	switch converted := value.(type) {
+	case time.Time:
+		return converted, false, nil
+	case string:
+		if parsed, err := time.ParseInLocation(dateLayout, converted, location); err == nil {
+			return parsed, true, nil
+		}
+
+		if parsed, err := time.Parse(time.RFC3339Nano, converted); err == nil {
+			return parsed, false, nil
+		}
+
+		for _, layout := range localDateTimeLayouts {
+			if parsed, err := time.ParseInLocation(layout, converted, location); err == nil {
+				return parsed, false, nil
+			}
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("cannot parse value %v as date", value)
+}
@@ -0,0 +1,76 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateRange(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2019, time.March, 31, 12, 0, 0, 0, berlin)
+	environment := NewEnvironment(berlin, "de", func() time.Time { return now })
+
+	tables := []struct {
+		value    interface{}
+		from, to time.Time
+	}{
+		// Daylight saving time starts, so the day lasts 23 hours
+		{"2019-03-31", time.Date(2019, time.March, 31, 0, 0, 0, 0, berlin), time.Date(2019, time.April, 1, 0, 0, 0, 0, berlin)},
+		{"2019-03-30T23:30:00Z", time.Date(2019, time.March, 31, 0, 0, 0, 0, berlin), time.Date(2019, time.April, 1, 0, 0, 0, 0, berlin)},
+		{"yesterday", time.Date(2019, time.March, 30, 0, 0, 0, 0, berlin), time.Date(2019, time.March, 31, 0, 0, 0, 0, berlin)},
+		{"last 2 days", time.Date(2019, time.March, 30, 0, 0, 0, 0, berlin), time.Date(2019, time.April, 1, 0, 0, 0, 0, berlin)},
+	}
+
+	for _, table := range tables {
+		from, to, err := DateRange(table.value, environment)
+		if err != nil {
+			t.Errorf("DateRange(%v) failed: %v.", table.value, err)
+			continue
+		}
+
+		if !from.Equal(table.from) || !to.Equal(table.to) {
+			t.Errorf("DateRange(%v) was incorrect, got: [%s, %s), want: [%s, %s).", table.value, from, to, table.from, table.to)
+		}
+	}
+
+	if _, _, err := DateRange("not a date", environment); err == nil {
+		t.Errorf("DateRange(not a date) did not fail.")
+	}
+}
+
+func TestDateTimeRange(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2019, time.March, 31, 12, 0, 0, 0, berlin)
+	environment := NewEnvironment(berlin, "de", func() time.Time { return now })
+
+	tables := []struct {
+		value    interface{}
+		from, to time.Time
+	}{
+		{"2019-03-31", time.Date(2019, time.March, 31, 0, 0, 0, 0, berlin), time.Date(2019, time.April, 1, 0, 0, 0, 0, berlin)},
+		{"2019-03-31 10:15", time.Date(2019, time.March, 31, 10, 15, 0, 0, berlin), time.Date(2019, time.March, 31, 10, 15, 1, 0, berlin)},
+		{"2019-03-31T10:15:30.5+02:00", time.Date(2019, time.March, 31, 8, 15, 30, 0, time.UTC), time.Date(2019, time.March, 31, 8, 15, 31, 0, time.UTC)},
+		{time.Date(2019, time.March, 31, 10, 15, 30, 0, time.UTC), time.Date(2019, time.March, 31, 10, 15, 30, 0, time.UTC), time.Date(2019, time.March, 31, 10, 15, 31, 0, time.UTC)},
+		{"today", time.Date(2019, time.March, 31, 0, 0, 0, 0, berlin), time.Date(2019, time.April, 1, 0, 0, 0, 0, berlin)},
+	}
+
+	for _, table := range tables {
+		from, to, err := DateTimeRange(table.value, environment)
+		if err != nil {
+			t.Errorf("DateTimeRange(%v) failed: %v.", table.value, err)
+			continue
+		}
+
+		if !from.Equal(table.from) || !to.Equal(table.to) {
+			t.Errorf("DateTimeRange(%v) was incorrect, got: [%s, %s), want: [%s, %s).", table.value, from, to, table.from, table.to)
+		}
+	}
+}
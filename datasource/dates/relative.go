@@ -0,0 +1,179 @@
+// Package dates resolves the date values of filters, independent of any data source. Values are
+// interpreted within the Environment of a request, which e.g. holds its time zone.
+package dates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Clock returns the current time. It is injectable, so relative dates can be resolved against
+// a fixed point in time, e.g. in tests.
+type Clock func() time.Time
+
+// Environment holds the settings of a request, which date values are interpreted in.
+type Environment struct {
+	// The time zone of the request. Values without an explicit time zone are interpreted in it.
+	Location *time.Location
+
+	// The locale of the request, which e.g. determinates the first day of a week.
+	Locale string
+
+	// The point in time, which relative dates are resolved against. It is fixed once per
+	// request, so all queries of a request agree on it.
+	Now time.Time
+}
+
+// NewEnvironment creates an Environment for the given time zone and locale, of which the
+// current time is taken from the clock. A nil location stands for UTC, and a nil clock for
+// time.Now.
+func NewEnvironment(location *time.Location, locale string, clock Clock) Environment {
+	if location == nil {
+		location = time.UTC
+	}
+
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return Environment{
+		Location: location,
+		Locale:   locale,
+		Now:      clock().In(location),
+	}
+}
+
+// Regions, of which weeks start on Sunday. Weeks of all other regions start on Monday.
+var sundayWeekRegions = map[string]struct{}{
+	"US": {}, "CA": {}, "MX": {}, "BR": {}, "JP": {}, "KR": {}, "TW": {}, "HK": {}, "IL": {},
+	"IN": {}, "PH": {}, "ZA": {}, "AU": {}, "SA": {},
+}
+
+// The regions assumed for locales, which only consist of a language.
+var defaultRegions = map[string]string{
+	"en": "US",
+	"ja": "JP",
+	"ko": "KR",
+	"he": "IL",
+	"pt": "BR",
+}
+
+// WeekStart returns the first day of a week for a locale like "de", "en-GB" or "en_US".
+func WeekStart(locale string) time.Weekday {
+	parts := strings.FieldsFunc(locale, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+
+	if len(parts) == 0 {
+		return time.Monday
+	}
+
+	region := defaultRegions[strings.ToLower(parts[0])]
+	if len(parts) > 1 {
+		region = strings.ToUpper(parts[len(parts)-1])
+	}
+
+	if _, isSundayWeek := sundayWeekRegions[region]; isSundayWeek {
+		return time.Sunday
+	}
+
+	return time.Monday
+}
+
+var (
+	relativeDayExpression    = regexp.MustCompile(`^(today|yesterday|tomorrow)$`)
+	relativePeriodExpression = regexp.MustCompile(`^(this|last|next) (week|month|quarter|year)$`)
+	relativeSpanExpression   = regexp.MustCompile(`^(last|next) (\d+) (day|week|month|quarter|year)s?$`)
+)
+
+// RelativeRange resolves a relative date expression into the half-open range of days [from, to)
+// it denotes, with both being midnight in the location of the environment. It reports, whether
+// the value is a relative date expression at all. Supported are:
+//
+//   - "today", "yesterday" and "tomorrow"
+//   - "this", "last" or "next", followed by "week", "month", "quarter" or "year", which denote
+//     the respective calendar period. Weeks start on the first day of a week of the locale.
+//   - "last" or "next", followed by a number of days, weeks, months, quarters or years, e.g.
+//     "last 7 days". These denote the span ending with today, or starting with today.
+//
+// Expressions are case insensitive.
+func RelativeRange(value string, environment Environment) (time.Time, time.Time, bool, error) {
+	expression := strings.ToLower(strings.Join(strings.Fields(value), " "))
+
+	now := environment.Now.In(environment.Location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, environment.Location)
+
+	if match := relativeDayExpression.FindStringSubmatch(expression); match != nil {
+		day := today
+		switch match[1] {
+		case "yesterday":
+			day = today.AddDate(0, 0, -1)
+		case "tomorrow":
+			day = today.AddDate(0, 0, 1)
+		}
+
+		return day, day.AddDate(0, 0, 1), true, nil
+	}
+
+	if match := relativePeriodExpression.FindStringSubmatch(expression); match != nil {
+		from := periodStart(today, match[2], WeekStart(environment.Locale))
+		switch match[1] {
+		case "last":
+			from = addPeriods(from, match[2], -1)
+		case "next":
+			from = addPeriods(from, match[2], 1)
+		}
+
+		return from, addPeriods(from, match[2], 1), true, nil
+	}
+
+	if match := relativeSpanExpression.FindStringSubmatch(expression); match != nil {
+		count, err := strconv.Atoi(match[2])
+		if err != nil || count <= 0 {
+			return time.Time{}, time.Time{}, true, fmt.Errorf("invalid relative date %s", value)
+		}
+
+		if match[1] == "last" {
+			to := today.AddDate(0, 0, 1)
+			return addPeriods(to, match[3], -count), to, true, nil
+		}
+
+		return today, addPeriods(today, match[3], count), true, nil
+	}
+
+	return time.Time{}, time.Time{}, false, nil
+}
+
+// Returns the start of the calendar period, which the day lies in.
+func periodStart(day time.Time, period string, weekStart time.Weekday) time.Time {
+	switch period {
+	case "week":
+		return day.AddDate(0, 0, -((int(day.Weekday()) - int(weekStart) + 7) % 7))
+	case "month":
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	case "quarter":
+		return time.Date(day.Year(), day.Month()-(day.Month()-1)%3, 1, 0, 0, 0, 0, day.Location())
+	default:
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, day.Location())
+	}
+}
+
+// Moves the day by a number of periods. Months, quarters and years are moved by the calendar,
+// so moving the last day of a month may overflow into the following month.
+func addPeriods(day time.Time, period string, count int) time.Time {
+	switch period {
+	case "day":
+		return day.AddDate(0, 0, count)
+	case "week":
+		return day.AddDate(0, 0, 7*count)
+	case "month":
+		return day.AddDate(0, count, 0)
+	case "quarter":
+		return day.AddDate(0, 3*count, 0)
+	default:
+		return day.AddDate(count, 0, 0)
+	}
+}
@@ -0,0 +1,28 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekStart(t *testing.T) {
+	tables := []struct {
+		locale string
+		want   time.Weekday
+	}{
+		{"", time.Monday},
+		{"de", time.Monday},
+		{"en", time.Sunday},
+		{"en-GB", time.Monday},
+		{"en_US", time.Sunday},
+		{"pt-PT", time.Monday},
+		{"de-CH", time.Monday},
+		{"ja", time.Sunday},
+	}
+
+	for _, table := range tables {
+		if got := WeekStart(table.locale); got != table.want {
+			t.Errorf("WeekStart(%s) was incorrect, got: %s, want: %s.", table.locale, got, table.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package datasource
+
+import (
+	"github.com/tableaux-project/tableaux"
+)
+
+// FacetRequest describes a request for the distinct values of a single path, and how many
+// rows have each value. The values are determined under the filters and the global search
+// of the underlying Request, while its limit, offset, orders and continuation are ignored.
+type FacetRequest struct {
+	request   Request
+	path      string
+	orderBy   tableaux.FacetOrder
+	direction tableaux.Order
+	limit     uint64
+}
+
+// NewFacetRequest constructs a new FacetRequest for the path, under the given Request. A
+// limit of 0 returns all distinct values.
+func NewFacetRequest(request Request, path string, orderBy tableaux.FacetOrder, direction tableaux.Order,
+	limit uint64) FacetRequest {
+	return FacetRequest{
+		request:   request,
+		path:      path,
+		orderBy:   orderBy,
+		direction: direction,
+		limit:     limit,
+	}
+}
+
+// Request returns the Request, of which the filters and the global search are applied.
+func (r FacetRequest) Request() Request {
+	return r.request
+}
+
+// Path returns the path of which the distinct values are requested.
+func (r FacetRequest) Path() string {
+	return r.path
+}
+
+// OrderBy returns by what the values are ordered.
+func (r FacetRequest) OrderBy() tableaux.FacetOrder {
+	return r.orderBy
+}
+
+// Direction returns the direction in which the values are ordered.
+func (r FacetRequest) Direction() tableaux.Order {
+	return r.direction
+}
+
+// Limit returns the maximum amount of values to be fetched, or 0 for no limit.
+func (r FacetRequest) Limit() uint64 {
+	return r.limit
+}
+
+// FacetResult is the result of a FacetRequest, with one FacetValue per distinct value.
+type FacetResult []FacetValue
+
+// FacetValue is a single distinct value of a facet.
+type FacetValue struct {
+	// Value is the type-safe value, which might be nil for rows without a value.
+	Value interface{}
+
+	// Count is the amount of rows having the value.
+	Count uint64
+
+	// TranslationKey is the translation key of the value, if the path is an enum column.
+	// Otherwise, it is empty.
+	TranslationKey string
+}
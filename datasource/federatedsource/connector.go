@@ -0,0 +1,247 @@
+// Package federatedsource implements a data source, which routes each request to one of
+// multiple underlying data sources (backends), depending on the schema of the request. This
+// allows serving schemas, which are spread across multiple databases or files, via a single
+// datasource.Connector.
+package federatedsource
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+)
+
+// Connector routes requests to the backend of their schema. The backend of a schema is taken
+// from the registered routes, or else from the "backend" attribute of the schema. Extension
+// schemas without a backend are expected to be served by the backend of the extending schema.
+type Connector struct {
+	backends     map[string]datasource.Connector
+	routes       map[string]string
+	schemaMapper config.SchemaMapper
+}
+
+// NewConnector creates a new federated Connector over the given backends, which are mapped by
+// name. The routes map schema keys to backend names, and take precedence over the backend
+// attribute of the schemas. The routes may be nil, if all schemas declare their backend. All
+// unknown schemas and backends are returned as datasource.ValidationErrors.
+func NewConnector(backends map[string]datasource.Connector, routes map[string]string, schemaMapper config.SchemaMapper) (datasource.Connector, error) {
+	var errs datasource.ValidationErrors
+
+	// Schemas are checked in order, so the problems are reported in a stable order
+	routedSchemas := make([]string, 0, len(routes))
+	for schema := range routes {
+		routedSchemas = append(routedSchemas, schema)
+	}
+	sort.Strings(routedSchemas)
+
+	for _, schema := range routedSchemas {
+		if _, err := schemaMapper.Schema(schema); err != nil {
+			errs.Add(datasource.ValidationUnknownSchema, datasource.CodeUnknownSchema, "", "unknown schema %s", schema)
+			continue
+		}
+
+		if backend := routes[schema]; backends[backend] == nil {
+			errs.Add(datasource.ValidationUnknownBackend, datasource.CodeUnknownBackend, "", "unknown backend %s for schema %s", backend, schema)
+		}
+	}
+
+	resolvedSchemas := schemaMapper.ResolvedSchemas()
+	schemas := make([]string, 0, len(resolvedSchemas))
+	for schema := range resolvedSchemas {
+		schemas = append(schemas, schema)
+	}
+	sort.Strings(schemas)
+
+	for _, schema := range schemas {
+		backend := resolvedSchemas[schema].OriginalSchema().Backend
+		if _, routed := routes[schema]; routed || backend == "" {
+			continue
+		}
+
+		if backends[backend] == nil {
+			errs.Add(datasource.ValidationUnknownBackend, datasource.CodeUnknownBackend, "", "unknown backend %s for schema %s", backend, schema)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Connector{
+		backends:     backends,
+		routes:       routes,
+		schemaMapper: schemaMapper,
+	}, nil
+}
+
+func (th Connector) ValidateRequest(request datasource.Request) error {
+	return th.ValidateRequestContext(context.Background(), request)
+}
+
+func (th Connector) ValidateRequestContext(ctx context.Context, request datasource.Request) error {
+	backend, err := th.requestBackend(request)
+	if err != nil {
+		return err
+	}
+
+	return backend.ValidateRequestContext(ctx, request)
+}
+
+func (th Connector) FetchData(request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	return th.FetchDataContext(context.Background(), request)
+}
+
+func (th Connector) FetchDataContext(ctx context.Context, request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	backend, err := th.requestBackend(request)
+	if err != nil {
+		return nil, 0, 0, "", err
+	}
+
+	return backend.FetchDataContext(ctx, request)
+}
+
+func (th Connector) FetchRows(ctx context.Context, request datasource.Request) (datasource.Rows, error) {
+	backend, err := th.requestBackend(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return backend.FetchRows(ctx, request)
+}
+
+func (th Connector) FetchFacets(ctx context.Context, request datasource.FacetRequest) (*datasource.FacetResult, error) {
+	backend, err := th.requestBackend(request.Request(), request.Path())
+	if err != nil {
+		return nil, err
+	}
+
+	return backend.FetchFacets(ctx, request)
+}
+
+// ValidateAggregation validates the request against the backend of its schema, which must
+// implement datasource.Aggregator.
+func (th Connector) ValidateAggregation(ctx context.Context, request datasource.AggregationRequest) error {
+	aggregator, err := th.aggregationBackend(request)
+	if err != nil {
+		return err
+	}
+
+	return aggregator.ValidateAggregation(ctx, request)
+}
+
+// FetchAggregation fetches the aggregation from the backend of its schema, which must
+// implement datasource.Aggregator.
+func (th Connector) FetchAggregation(ctx context.Context, request datasource.AggregationRequest) (*datasource.AggregationResult, error) {
+	aggregator, err := th.aggregationBackend(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregator.FetchAggregation(ctx, request)
+}
+
+// Returns the backend for a request, after checking that all of its paths can be served by it.
+// Additional paths (e.g. of a facet) can be given, which are checked as well.
+func (th Connector) requestBackend(request datasource.Request, additionalPaths ...string) (datasource.Connector, error) {
+	paths := append(append([]string{}, request.Columns()...), additionalPaths...)
+
+	if filterExpression := request.FilterExpression(); filterExpression != nil {
+		paths = append(paths, filterExpression.Paths()...)
+	}
+
+	for _, order := range request.Orders() {
+		paths = append(paths, order.Path())
+	}
+
+	return th.backend(request.Schema(), paths)
+}
+
+// Returns the backend for an aggregation request, after checking that all of its paths can be
+// served by it, and that it supports aggregations.
+func (th Connector) aggregationBackend(request datasource.AggregationRequest) (datasource.Aggregator, error) {
+	paths := append([]string{}, request.GroupBy()...)
+
+	for _, aggregation := range request.Aggregations() {
+		paths = append(paths, aggregation.Path())
+	}
+
+	if filterExpression := request.FilterExpression(); filterExpression != nil {
+		paths = append(paths, filterExpression.Paths()...)
+	}
+
+	// Orders may also refer to aliases, which are ignored as they are no column paths
+	for _, order := range request.Orders() {
+		paths = append(paths, order.Path())
+	}
+
+	backend, err := th.backend(request.Schema(), paths)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregator, isAggregator := backend.(datasource.Aggregator)
+	if !isAggregator {
+		var errs datasource.ValidationErrors
+		errs.Add(datasource.ValidationInvalidRequest, datasource.CodeAggregationsUnsupported, "",
+			"backend %s of schema %s does not support aggregations", th.route(request.Schema()), request.Schema())
+		return nil, errs
+	}
+
+	return aggregator, nil
+}
+
+// Returns the backend of a schema, and checks that all given paths can be served by it. That
+// is, paths joined from other schemas must be served by the same backend. Unknown paths are
+// ignored, and left to the validation of the backend. Problems are returned as
+// datasource.ValidationErrors.
+func (th Connector) backend(schema string, paths []string) (datasource.Connector, error) {
+	var errs datasource.ValidationErrors
+
+	columnSchemas, err := th.schemaMapper.ColumnSchemas(schema)
+	if err != nil {
+		errs.Add(datasource.ValidationUnknownSchema, datasource.CodeUnknownSchema, "", "unknown schema %s", schema)
+		return nil, errs
+	}
+
+	backendName := th.route(schema)
+	if backendName == "" {
+		errs.Add(datasource.ValidationUnknownBackend, datasource.CodeNoBackend, "", "no backend for schema %s", schema)
+		return nil, errs
+	}
+
+	checked := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		columnSchema, exists := columnSchemas[path]
+		if !exists || columnSchema == schema || checked[path] {
+			continue
+		}
+		checked[path] = true
+
+		if pathBackend := th.route(columnSchema); pathBackend != "" && pathBackend != backendName {
+			errs.Add(datasource.ValidationUnsupportedJoin, datasource.CodeCrossBackendPath, path,
+				"path %s of schema %s is served by backend %s, and cannot be joined with backend %s of schema %s",
+				path, columnSchema, pathBackend, backendName, schema)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return th.backends[backendName], nil
+}
+
+// Returns the name of the backend of a schema, or an empty string if there is none.
+func (th Connector) route(schema string) string {
+	if backend, routed := th.routes[schema]; routed {
+		return backend
+	}
+
+	tableSchema, err := th.schemaMapper.Schema(schema)
+	if err != nil {
+		return ""
+	}
+
+	return tableSchema.Backend
+}
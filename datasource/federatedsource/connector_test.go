@@ -0,0 +1,171 @@
+package federatedsource
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/memsource"
+)
+
+func newTestConnector(t *testing.T, routes map[string]string) (datasource.Connector, error) {
+	enumMapper, err := config.NewEnumMapperFromFolder(filepath.Join("testfiles", "enum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translator, err := config.NewTranslatorFromFolder(filepath.Join("testfiles", "i18n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaMapper, err := config.NewSchemaMapperFromFolder(filepath.Join("testfiles", "schema"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	people, err := memsource.NewConnector(map[string]memsource.Table{
+		"persons": {
+			{"person_id": int64(1), "person_name": "Alice", "person_organization_name": "Acme"},
+			{"person_id": int64(2), "person_name": "Bob"},
+		},
+	}, enumMapper, translator, schemaMapper)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	organizations, err := memsource.NewConnector(map[string]memsource.Table{
+		"organizations": {
+			{"organization_id": int64(1), "organization_name": "Acme"},
+		},
+	}, enumMapper, translator, schemaMapper)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewConnector(map[string]datasource.Connector{
+		"people":        people,
+		"organizations": organizations,
+	}, routes, schemaMapper)
+}
+
+func TestFetchDataRouting(t *testing.T) {
+	connector, err := newTestConnector(t, map[string]string{"organizations": "organizations"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := []struct {
+		request datasource.Request
+		result  datasource.Result
+	}{
+		{datasource.NewRequestBuilder("persons").Columns("person_name").Locale("en").Build(),
+			datasource.Result{{"person_name": "Alice"}, {"person_name": "Bob"}}},
+		{datasource.NewRequestBuilder("organizations").Columns("organization_name").Locale("en").Build(),
+			datasource.Result{{"organization_name": "Acme"}}},
+	}
+
+	for _, table := range tables {
+		result, _, _, _, err := connector.FetchData(table.request)
+		if err != nil {
+			t.Fatalf("FetchData failed: %s", err)
+		}
+
+		if !reflect.DeepEqual(*result, table.result) {
+			t.Errorf("FetchData of %s was incorrect, got: %v, want: %v.", table.request.Schema(), *result, table.result)
+		}
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	tables := []struct {
+		name    string
+		routes  map[string]string
+		request datasource.Request
+		code    datasource.ValidationCode
+		err     string
+	}{
+		{"own paths", map[string]string{"organizations": "organizations"},
+			datasource.NewRequestBuilder("persons").Columns("person_name").Locale("en").Build(), "", ""},
+		{"joined column across backends", map[string]string{"organizations": "organizations"},
+			datasource.NewRequestBuilder("persons").Columns("person_name", "person_organization_name").Locale("en").Build(),
+			datasource.CodeCrossBackendPath,
+			"path person_organization_name of schema organizations is served by backend organizations, and cannot be joined with backend people of schema persons"},
+		{"joined filter across backends", map[string]string{"organizations": "organizations"},
+			datasource.NewRequestBuilder("persons").Columns("person_name").Locale("en").
+				Filters(datasource.NewSimpleFilterGroup("person_organization_name", tableaux.FilterEquals, []interface{}{"Acme"})).Build(),
+			datasource.CodeCrossBackendPath, "path person_organization_name"},
+		{"joined order across backends", map[string]string{"organizations": "organizations"},
+			datasource.NewRequestBuilder("persons").Columns("person_name").Locale("en").
+				Orders(datasource.NewOrder("person_organization_name", tableaux.OrderAsc, nil)).Build(),
+			datasource.CodeCrossBackendPath, "path person_organization_name"},
+		{"joined column without backend", nil,
+			datasource.NewRequestBuilder("persons").Columns("person_name", "person_organization_name").Locale("en").Build(), "", ""},
+		{"schema without backend", nil,
+			datasource.NewRequestBuilder("organizations").Columns("organization_name").Locale("en").Build(),
+			datasource.CodeNoBackend, "no backend for schema organizations"},
+		{"unknown schema", nil,
+			datasource.NewRequestBuilder("unknown").Columns("unknown_name").Locale("en").Build(),
+			datasource.CodeUnknownSchema, "unknown schema unknown"},
+		{"backend validation", nil,
+			datasource.NewRequestBuilder("persons").Columns("person_unknown").Locale("en").Build(),
+			datasource.CodeUnknownColumn, "unknown column person_unknown"},
+	}
+
+	for _, table := range tables {
+		connector, err := newTestConnector(t, table.routes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = connector.ValidateRequest(table.request)
+		if (table.err == "" && err != nil) || (table.err != "" && (err == nil || !strings.Contains(err.Error(), table.err))) {
+			t.Errorf("ValidateRequest with %s was incorrect, got: %v, want: %q.", table.name, err, table.err)
+			continue
+		}
+
+		if table.err == "" {
+			continue
+		}
+
+		if errs, isValidation := err.(datasource.ValidationErrors); !isValidation || len(errs) != 1 || errs[0].Code != table.code {
+			t.Errorf("ValidateRequest with %s was incorrect, got: %#v, want: code %s.", table.name, err, table.code)
+		}
+	}
+}
+
+func TestFetchAggregationUnsupported(t *testing.T) {
+	connector, err := newTestConnector(t, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := datasource.NewAggregationRequestBuilder("persons").
+		Aggregations(datasource.NewAggregation(tableaux.AggregateCount, "")).
+		Locale("en").
+		Build()
+
+	want := datasource.ValidationErrors{{
+		Kind:    datasource.ValidationInvalidRequest,
+		Code:    datasource.CodeAggregationsUnsupported,
+		Message: "backend people of schema persons does not support aggregations",
+	}}
+	if _, err := connector.(datasource.Aggregator).FetchAggregation(context.Background(), request); !reflect.DeepEqual(err, want) {
+		t.Errorf("FetchAggregation was incorrect, got: %#v, want: %#v.", err, want)
+	}
+}
+
+func TestNewConnectorUnknownBackend(t *testing.T) {
+	want := datasource.ValidationErrors{
+		{Kind: datasource.ValidationUnknownBackend, Code: datasource.CodeUnknownBackend, Message: "unknown backend archive for schema organizations"},
+		{Kind: datasource.ValidationUnknownSchema, Code: datasource.CodeUnknownSchema, Message: "unknown schema unknown"},
+	}
+	if _, err := newTestConnector(t, map[string]string{"organizations": "archive", "unknown": "people"}); !reflect.DeepEqual(err, want) {
+		t.Errorf("NewConnector was incorrect, got: %#v, want: %#v.", err, want)
+	}
+}
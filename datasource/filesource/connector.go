@@ -0,0 +1,257 @@
+// Package filesource implements a data source, which serves data from CSV and JSON Lines files.
+// The files are read once on creation, and then served via the in-memory data source, so the
+// standard filters, orders and paging behave exactly like they do for memsource.
+package filesource
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/birkirb/loggers.v1/log"
+
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/memsource"
+)
+
+// Format is the format of a data file.
+type Format string
+
+const (
+	// FormatCSV is a comma separated file, with a header row naming the columns.
+	FormatCSV Format = "csv"
+
+	// FormatJSONLines is a file with one JSON object per line.
+	FormatJSONLines Format = "jsonl"
+)
+
+// File describes the data file of a single entity. The columns of the file are named by the
+// (unprefixed) column paths of the entity schema, e.g. "person_name".
+type File struct {
+	// The path to the file.
+	Path string
+
+	// The format of the file. If empty, it is derived from the file extension.
+	Format Format
+
+	// The column holding the primary key of the entity. It is only required, if another
+	// schema extends the entity.
+	PrimaryKey string
+
+	// Maps the keys of the schema extensions (see config.TableSchemaExtensionTable) to the
+	// columns holding the primary key of the extending entity, e.g. "organization" to
+	// "organization_id".
+	ForeignKeys map[string]string
+}
+
+// A loaded data file, with its rows and the rows indexed by primary key.
+type entityData struct {
+	file  File
+	rows  []map[string]interface{}
+	index map[string]map[string]interface{}
+}
+
+// NewConnector creates a new file-backed Connector, which serves the given files. The files
+// are mapped by the entity of their schema (see config.TableSchema), and each schema with a
+// file for its entity is served. Extensions are resolved by joining the files via the declared
+// keys. The options are passed on to memsource.NewConnector.
+func NewConnector(files map[string]File, enumMapper config.EnumMapper, translator config.Translator, schemaMapper config.SchemaMapper,
+	options ...memsource.Option) (datasource.Connector, error) {
+	tables, err := loadTables(files, schemaMapper)
+	if err != nil {
+		return nil, err
+	}
+
+	return memsource.NewConnector(tables, enumMapper, translator, schemaMapper, options...)
+}
+
+// Loads the files, and assembles a memsource.Table for each schema with a file for its entity.
+func loadTables(files map[string]File, schemaMapper config.SchemaMapper) (map[string]memsource.Table, error) {
+	schemas := schemaMapper.ResolvedSchemas()
+
+	// Each entity may be used by multiple schemas, so the files are only read once
+	entities := make(map[string]*entityData)
+	for _, schema := range schemas {
+		if err := loadEntity(schema.OriginalSchema(), files, schemaMapper, entities); err != nil {
+			return nil, err
+		}
+	}
+
+	tables := make(map[string]memsource.Table)
+	for key, schema := range schemas {
+		entity, exists := entities[schema.OriginalSchema().Entity]
+		if !exists {
+			continue
+		}
+
+		table := make(memsource.Table, len(entity.rows))
+		for i, row := range entity.rows {
+			table[i] = make(map[string]interface{})
+			if err := joinRow(table[i], row, schema.OriginalSchema(), "", schemaMapper, entities); err != nil {
+				return nil, err
+			}
+		}
+
+		tables[key] = table
+
+		log.WithFields(
+			"schema", key,
+			"rows", len(table),
+		).Debug("Loaded table from file")
+	}
+
+	return tables, nil
+}
+
+// Loads the file of the entity of a schema, and the files of its extensions. Schemas without
+// a file for their entity are skipped, while extensions without a file are an error.
+func loadEntity(schema config.TableSchema, files map[string]File, schemaMapper config.SchemaMapper,
+	entities map[string]*entityData) error {
+	if _, loaded := entities[schema.Entity]; loaded {
+		return nil
+	}
+
+	file, exists := files[schema.Entity]
+	if !exists {
+		return nil
+	}
+
+	rows, err := readFile(file, schema)
+	if err != nil {
+		return err
+	}
+
+	entity := &entityData{file: file, rows: rows}
+	entities[schema.Entity] = entity
+
+	for _, extension := range schema.Extensions {
+		extensionSchema, err := schemaMapper.Schema(extension.Table)
+		if err != nil {
+			return fmt.Errorf("unknown extension schema %s", extension.Table)
+		}
+
+		if _, exists := files[extensionSchema.Entity]; !exists {
+			return fmt.Errorf("no file for extended entity %s", extensionSchema.Entity)
+		}
+
+		if _, exists := file.ForeignKeys[extension.Key]; !exists {
+			return fmt.Errorf("no foreign key for extension %s of entity %s", extension.Key, schema.Entity)
+		}
+
+		if err := loadEntity(extensionSchema, files, schemaMapper, entities); err != nil {
+			return err
+		}
+
+		if err := entities[extensionSchema.Entity].buildIndex(extensionSchema.Entity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Indexes the rows of an entity by their primary key, so they can be joined.
+func (entity *entityData) buildIndex(name string) error {
+	if entity.index != nil {
+		return nil
+	}
+
+	if entity.file.PrimaryKey == "" {
+		return fmt.Errorf("no primary key for extended entity %s", name)
+	}
+
+	entity.index = make(map[string]map[string]interface{}, len(entity.rows))
+	for _, row := range entity.rows {
+		value := row[entity.file.PrimaryKey]
+		if value == nil {
+			continue
+		}
+
+		key := fmt.Sprint(value)
+		if _, duplicate := entity.index[key]; duplicate {
+			return fmt.Errorf("duplicate primary key %s in file %s", key, entity.file.Path)
+		}
+
+		entity.index[key] = row
+	}
+
+	return nil
+}
+
+// Copies the columns of a row into the target row, prefixed like the resolved schema does it,
+// and recursively joins the rows of the extensions. Missing extension rows leave their columns
+// empty, like a left join.
+func joinRow(target, row map[string]interface{}, schema config.TableSchema, prefix string,
+	schemaMapper config.SchemaMapper, entities map[string]*entityData) error {
+	for _, column := range schema.Columns {
+		target[prefixedPath(column.Path, prefix)] = row[column.Path]
+	}
+
+	for _, extension := range schema.Extensions {
+		extensionSchema, err := schemaMapper.Schema(extension.Table)
+		if err != nil {
+			return fmt.Errorf("unknown extension schema %s", extension.Table)
+		}
+
+		entity := entities[extensionSchema.Entity]
+
+		foreignKey := row[entities[schema.Entity].file.ForeignKeys[extension.Key]]
+		if foreignKey == nil {
+			continue
+		}
+
+		extensionRow, exists := entity.index[fmt.Sprint(foreignKey)]
+		if !exists {
+			continue
+		}
+
+		if err := joinRow(target, extensionRow, extensionSchema, extensionPrefix(schema.Entity, prefix, extension.Key),
+			schemaMapper, entities); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Calculates the prefix of the columns of an extension, the same way the SchemaMapper does.
+func extensionPrefix(entity, prefix, key string) string {
+	extensionString := entity
+	if prefix != "" {
+		extensionString = prefix
+	}
+
+	if extensionString != "" && key != "" {
+		return extensionString + "_" + key
+	} else if key != "" {
+		return key
+	}
+
+	return extensionString
+}
+
+// Prefixes a column path, the same way the SchemaMapper does.
+func prefixedPath(path, prefix string) string {
+	if prefix == "" {
+		return path
+	}
+
+	return prefix + "_" + path[strings.Index(path, "_")+1:]
+}
+
+// Returns the format of a file, which is derived from the file extension if not declared.
+func (file File) format() (Format, error) {
+	if file.Format != "" {
+		return file.Format, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(file.Path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONLines, nil
+	default:
+		return "", fmt.Errorf("cannot derive format of file %s", file.Path)
+	}
+}
@@ -0,0 +1,184 @@
+package filesource
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/memsource"
+)
+
+func newTestMappers(t *testing.T) (config.EnumMapper, config.Translator, config.SchemaMapper) {
+	enumMapper, err := config.NewEnumMapperFromFolder(filepath.Join("testfiles", "enum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translator, err := config.NewTranslatorFromFolder(filepath.Join("testfiles", "i18n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaMapper, err := config.NewSchemaMapperFromFolder(filepath.Join("testfiles", "schema"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return enumMapper, translator, schemaMapper
+}
+
+func testFiles() map[string]File {
+	return map[string]File{
+		"person": {
+			Path:        filepath.Join("testfiles", "data", "persons.csv"),
+			ForeignKeys: map[string]string{"organization": "organization_id"},
+		},
+		"organization": {
+			Path:       filepath.Join("testfiles", "data", "organizations.jsonl"),
+			PrimaryKey: "organization_id",
+		},
+	}
+}
+
+func TestFetchData(t *testing.T) {
+	enumMapper, translator, schemaMapper := newTestMappers(t)
+
+	connector, err := NewConnector(testFiles(), enumMapper, translator, schemaMapper)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id", "person_name", "person_active", "person_birthday", "person_organization_name").
+		Filters(datasource.NewSimpleFilterGroup("person_country", tableaux.FilterEquals, []interface{}{"DE", "AT"})).
+		Orders(datasource.NewOrder("person_organization_name", tableaux.OrderDesc, nil), datasource.NewOrder("person_id", tableaux.OrderAsc, nil)).
+		Limit(3).
+		Offset(1).
+		Locale("en").
+		Build()
+
+	result, totalCount, filteredCount, _, err := connector.FetchData(request)
+	if err != nil {
+		t.Fatalf("FetchData failed: %s", err)
+	}
+
+	if totalCount != 5 || filteredCount != 4 {
+		t.Errorf("Counts were incorrect, got: %d/%d, want: 5/4.", totalCount, filteredCount)
+	}
+
+	want := datasource.Result{
+		{"person_id": int64(3), "person_name": "Carol", "person_active": true, "person_birthday": nil, "person_organization_name": "Acme"},
+		{"person_id": int64(4), "person_name": "Dave", "person_active": true, "person_birthday": "2001-02-28", "person_organization_name": nil},
+		{"person_id": int64(5), "person_name": "O'Brien, Pat", "person_active": false, "person_birthday": "1977-03-15", "person_organization_name": nil},
+	}
+
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("FetchData was incorrect, got: %v, want: %v.", *result, want)
+	}
+}
+
+func TestFetchDataClock(t *testing.T) {
+	enumMapper, translator, schemaMapper := newTestMappers(t)
+
+	clock := memsource.WithClock(func() time.Time {
+		return time.Date(2001, time.March, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	connector, err := NewConnector(testFiles(), enumMapper, translator, schemaMapper, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(datasource.NewSimpleFilterGroup("person_birthday", tableaux.FilterEquals, []interface{}{"yesterday"})).
+		Build()
+
+	result, _, _, _, err := connector.FetchData(request)
+	if err != nil {
+		t.Fatalf("FetchData failed: %s", err)
+	}
+
+	if want := (datasource.Result{{"person_id": int64(4)}}); !reflect.DeepEqual(*result, want) {
+		t.Errorf("FetchData was incorrect, got: %v, want: %v.", *result, want)
+	}
+}
+
+func TestNewConnectorErrors(t *testing.T) {
+	enumMapper, translator, schemaMapper := newTestMappers(t)
+
+	tables := []struct {
+		name   string
+		modify func(files map[string]File)
+		err    string
+	}{
+		{"missing extension file", func(files map[string]File) { delete(files, "organization") }, "no file for extended entity organization"},
+		{"missing primary key", func(files map[string]File) {
+			organization := files["organization"]
+			organization.PrimaryKey = ""
+			files["organization"] = organization
+		}, "no primary key for extended entity organization"},
+		{"missing foreign key", func(files map[string]File) {
+			person := files["person"]
+			person.ForeignKeys = nil
+			files["person"] = person
+		}, "no foreign key for extension organization of entity person"},
+		{"unknown format", func(files map[string]File) {
+			person := files["person"]
+			person.Path = filepath.Join("testfiles", "data", "persons.txt")
+			files["person"] = person
+		}, "cannot derive format of file"},
+		{"invalid value", func(files map[string]File) {
+			person := files["person"]
+			person.Path = filepath.Join("testfiles", "data", "invalid.jsonl")
+			files["person"] = person
+		}, "is not an integer"},
+	}
+
+	for _, table := range tables {
+		files := testFiles()
+		table.modify(files)
+
+		if _, err := NewConnector(files, enumMapper, translator, schemaMapper); err == nil || !strings.Contains(err.Error(), table.err) {
+			t.Errorf("NewConnector with %s was incorrect, got: %v, want: %s.", table.name, err, table.err)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tables := []struct {
+		value      interface{}
+		columnType string
+		parsed     interface{}
+		valid      bool
+	}{
+		{nil, "long", nil, true},
+		{"42", "long", int64(42), true},
+		{json.Number("42"), "integer", int64(42), true},
+		{json.Number("4.2"), "integer", nil, false},
+		{"abc", "long", nil, false},
+		{"true", "boolean", true, true},
+		{false, "boolean", false, true},
+		{"yes", "boolean", nil, false},
+		{"2018-05-01", "date", "2018-05-01", true},
+		{"2018-05-01 12:30:00", "datetime", "2018-05-01 12:30:00", true},
+		{"01.05.2018", "date", nil, false},
+		{"DE", "country", "DE", true},
+		{json.Number("7"), "string", "7", true},
+		{[]interface{}{}, "string", nil, false},
+	}
+
+	for _, table := range tables {
+		parsed, err := parseValue(table.value, table.columnType)
+		if (err == nil) != table.valid || !reflect.DeepEqual(parsed, table.parsed) {
+			t.Errorf("parseValue(%v, %s) was incorrect, got: %v (%v), want: %v (valid: %t).",
+				table.value, table.columnType, parsed, err, table.parsed, table.valid)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package filesource
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tableaux-project/tableaux/config"
+)
+
+// Reads all rows of a file, and converts the values of the schema columns to their column type.
+// Other columns (e.g. foreign keys) are kept as they are read.
+func readFile(file File, schema config.TableSchema) ([]map[string]interface{}, error) {
+	format, err := file.format()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := os.Open(file.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	var rows []map[string]interface{}
+	switch format {
+	case FormatCSV:
+		rows, err = readCSV(reader, schema)
+	case FormatJSONLines:
+		rows, err = readJSONLines(reader)
+	default:
+		return nil, fmt.Errorf("unknown format %s of file %s", format, file.Path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file %s: %s", file.Path, err)
+	}
+
+	for i, row := range rows {
+		for _, column := range schema.Columns {
+			value, err := parseValue(row[column.Path], column.Type)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value in row %d of file %s: column %s: %s", i+1, file.Path, column.Path, err)
+			}
+
+			row[column.Path] = value
+		}
+	}
+
+	return rows, nil
+}
+
+// Reads a CSV file with a header row. Empty values are read as nil. All columns of the schema
+// must be present in the header.
+func readCSV(reader io.Reader, schema config.TableSchema) ([]map[string]interface{}, error) {
+	csvReader := csv.NewReader(reader)
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("missing header")
+	} else if err != nil {
+		return nil, err
+	}
+
+	present := make(map[string]struct{}, len(header))
+	for _, name := range header {
+		present[name] = struct{}{}
+	}
+
+	for _, column := range schema.Columns {
+		if _, exists := present[column.Path]; !exists {
+			return nil, fmt.Errorf("missing column %s", column.Path)
+		}
+	}
+
+	var rows []map[string]interface{}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			if record[i] != "" {
+				row[name] = record[i]
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// Reads a JSON Lines file. Empty lines are skipped, and missing keys are read as nil. Numbers
+// are read as json.Number, so large integers do not lose precision.
+func readJSONLines(reader io.Reader) ([]map[string]interface{}, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	var rows []map[string]interface{}
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+
+		var row map[string]interface{}
+		if err := decoder.Decode(&row); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+
+		if row == nil {
+			return nil, fmt.Errorf("line %d: not an object", line)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, scanner.Err()
+}
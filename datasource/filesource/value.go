@@ -0,0 +1,78 @@
+package filesource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The layouts, which are accepted for date and datetime columns.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Converts a value read from a file to the type of its column. Booleans are converted to bool,
+// integers and longs to int64, and all other types (including enums) to string. Dates and
+// datetimes are validated, but kept as string, which is what the in-memory filters expect.
+func parseValue(value interface{}, columnType string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch strings.ToLower(columnType) {
+	case "boolean":
+		switch converted := value.(type) {
+		case bool:
+			return converted, nil
+		case string:
+			parsed, err := strconv.ParseBool(converted)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a boolean", converted)
+			}
+
+			return parsed, nil
+		}
+	case "integer", "long":
+		switch converted := value.(type) {
+		case json.Number:
+			parsed, err := converted.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("%q is not an integer", converted)
+			}
+
+			return parsed, nil
+		case string:
+			parsed, err := strconv.ParseInt(converted, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not an integer", converted)
+			}
+
+			return parsed, nil
+		}
+	case "date", "datetime":
+		if converted, isString := value.(string); isString {
+			for _, layout := range timeLayouts {
+				if _, err := time.Parse(layout, converted); err == nil {
+					return converted, nil
+				}
+			}
+
+			return nil, fmt.Errorf("%q is not a %s", converted, strings.ToLower(columnType))
+		}
+	default:
+		switch converted := value.(type) {
+		case string:
+			return converted, nil
+		case json.Number:
+			return converted.String(), nil
+		case bool:
+			return strconv.FormatBool(converted), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected value %v of type %T", value, value)
+}
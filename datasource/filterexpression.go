@@ -0,0 +1,232 @@
+package datasource
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// FilterExpression is a node of a boolean filter tree. The leaves of the tree are
+// FilterGroups, which filter a single path, while AndExpression, OrExpression and
+// NotExpression combine them into arbitrary conditions across multiple paths.
+type FilterExpression interface {
+	// Paths returns all paths which are filtered on by the expression.
+	Paths() []string
+}
+
+// AndExpression is a FilterExpression, which matches if all of its expressions match.
+// An empty AndExpression matches everything.
+type AndExpression struct {
+	expressions []FilterExpression
+}
+
+// NewAndExpression constructs a new AndExpression.
+func NewAndExpression(expressions ...FilterExpression) AndExpression {
+	return AndExpression{
+		expressions: expressions,
+	}
+}
+
+// Expressions returns all expressions which are to be AND'ed.
+func (e AndExpression) Expressions() []FilterExpression {
+	return e.expressions
+}
+
+// Paths returns all paths which are filtered on by the expression.
+func (e AndExpression) Paths() []string {
+	return pathsOfExpressions(e.expressions)
+}
+
+// OrExpression is a FilterExpression, which matches if any of its expressions match.
+// An empty OrExpression matches nothing.
+type OrExpression struct {
+	expressions []FilterExpression
+}
+
+// NewOrExpression constructs a new OrExpression.
+func NewOrExpression(expressions ...FilterExpression) OrExpression {
+	return OrExpression{
+		expressions: expressions,
+	}
+}
+
+// Expressions returns all expressions which are to be OR'ed.
+func (e OrExpression) Expressions() []FilterExpression {
+	return e.expressions
+}
+
+// Paths returns all paths which are filtered on by the expression.
+func (e OrExpression) Paths() []string {
+	return pathsOfExpressions(e.expressions)
+}
+
+// NotExpression is a FilterExpression, which negates another expression.
+type NotExpression struct {
+	expression FilterExpression
+}
+
+// NewNotExpression constructs a new NotExpression.
+func NewNotExpression(expression FilterExpression) NotExpression {
+	return NotExpression{
+		expression: expression,
+	}
+}
+
+// Expression returns the negated expression.
+func (e NotExpression) Expression() FilterExpression {
+	return e.expression
+}
+
+// Paths returns all paths which are filtered on by the expression.
+func (e NotExpression) Paths() []string {
+	if e.expression == nil {
+		return nil
+	}
+
+	return e.expression.Paths()
+}
+
+// Paths returns the path of the FilterGroup, which makes a FilterGroup the leaf
+// of a FilterExpression tree.
+func (f FilterGroup) Paths() []string {
+	return []string{f.path}
+}
+
+// Combines FilterGroups and an optional FilterExpression into a single AND'ed expression.
+// If there are no filters at all, nil is returned.
+func combineFilters(filters []FilterGroup, where FilterExpression) FilterExpression {
+	expressions := make([]FilterExpression, 0, len(filters)+1)
+	for _, filterGroup := range filters {
+		expressions = append(expressions, filterGroup)
+	}
+
+	if where != nil {
+		expressions = append(expressions, where)
+	}
+
+	switch len(expressions) {
+	case 0:
+		return nil
+	case 1:
+		return expressions[0]
+	default:
+		return NewAndExpression(expressions...)
+	}
+}
+
+// FilterGroupsOf returns all FilterGroups of a FilterExpression tree, in order of appearance.
+func FilterGroupsOf(expression FilterExpression) []FilterGroup {
+	switch node := expression.(type) {
+	case FilterGroup:
+		return []FilterGroup{node}
+	case AndExpression:
+		return filterGroupsOfExpressions(node.expressions)
+	case OrExpression:
+		return filterGroupsOfExpressions(node.expressions)
+	case NotExpression:
+		return FilterGroupsOf(node.expression)
+	default:
+		return nil
+	}
+}
+
+func filterGroupsOfExpressions(expressions []FilterExpression) []FilterGroup {
+	var filterGroups []FilterGroup
+	for _, expression := range expressions {
+		filterGroups = append(filterGroups, FilterGroupsOf(expression)...)
+	}
+
+	return filterGroups
+}
+
+func pathsOfExpressions(expressions []FilterExpression) []string {
+	var paths []string
+	for _, expression := range expressions {
+		paths = append(paths, expression.Paths()...)
+	}
+
+	return paths
+}
+
+// ----------------------------------------------------------------------------
+// Wire format
+// ----------------------------------------------------------------------------
+
+// MarshalJSON encodes the AndExpression as {"and": [...]}.
+func (e AndExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string][]FilterExpression{"and": e.expressions})
+}
+
+// MarshalJSON encodes the OrExpression as {"or": [...]}.
+func (e OrExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string][]FilterExpression{"or": e.expressions})
+}
+
+// MarshalJSON encodes the NotExpression as {"not": {...}}.
+func (e NotExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]FilterExpression{"not": e.expression})
+}
+
+// Decodes a FilterExpression, which is either a combining expression, or a FilterGroup.
+func unmarshalFilterExpression(data []byte) (FilterExpression, error) {
+	var node map[string]json.RawMessage
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	if rawExpressions, isAnd := node["and"]; isAnd {
+		expressions, err := unmarshalFilterExpressions(rawExpressions)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewAndExpression(expressions...), nil
+	}
+
+	if rawExpressions, isOr := node["or"]; isOr {
+		expressions, err := unmarshalFilterExpressions(rawExpressions)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewOrExpression(expressions...), nil
+	}
+
+	if rawExpression, isNot := node["not"]; isNot {
+		expression, err := unmarshalFilterExpression(rawExpression)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewNotExpression(expression), nil
+	}
+
+	if _, isGroup := node["path"]; isGroup {
+		var filterGroup FilterGroup
+		if err := json.Unmarshal(data, &filterGroup); err != nil {
+			return nil, err
+		}
+
+		return filterGroup, nil
+	}
+
+	return nil, errors.New("unknown filter expression")
+}
+
+func unmarshalFilterExpressions(data []byte) ([]FilterExpression, error) {
+	var rawExpressions []json.RawMessage
+	if err := json.Unmarshal(data, &rawExpressions); err != nil {
+		return nil, err
+	}
+
+	var expressions []FilterExpression
+	for _, rawExpression := range rawExpressions {
+		expression, err := unmarshalFilterExpression(rawExpression)
+		if err != nil {
+			return nil, err
+		}
+
+		expressions = append(expressions, expression)
+	}
+
+	return expressions, nil
+}
@@ -0,0 +1,368 @@
+// Package memsource implements a data source, which serves data from memory. It is meant for
+// tests and small data sets, and serves as the reference semantics for other data sources.
+package memsource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/birkirb/loggers.v1/log"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+)
+
+// Connector is the entry point for in-memory data. It serves one Table per schema.
+type Connector struct {
+	enumMapper   config.EnumMapper
+	schemaMapper config.SchemaMapper
+	translator   config.Translator
+	tables       map[string]Table
+	sorters      map[string]Sorter
+	filters      map[string]Filter
+
+	// The clock, which relative dates in filters are resolved against
+	clock dates.Clock
+}
+
+// NewConnector creates a new in-memory Connector, which serves the given tables. The tables
+// are mapped by the key of their schema, under which it is known to the SchemaMapper. The
+// tables must not be modified afterwards. Options can e.g. pin the clock, which relative dates
+// are resolved against.
+func NewConnector(tables map[string]Table, enumMapper config.EnumMapper, translator config.Translator, schemaMapper config.SchemaMapper,
+	options ...Option) (datasource.Connector, error) {
+	if err := schemaMapper.ValidateIntegrity(enumMapper); err != nil {
+		return nil, err
+	}
+
+	for schema := range tables {
+		if _, err := schemaMapper.ResolvedSchema(schema); err != nil {
+			return nil, fmt.Errorf("unknown schema %s", schema)
+		}
+	}
+
+	connector := &Connector{
+		enumMapper,
+		schemaMapper,
+		translator,
+		tables,
+		map[string]Sorter{
+			"":               directSorter{},
+			"EnumOrder":      enumSorter{mapper: enumMapper, translator: translator},
+			"ShortEnumOrder": enumSorter{mapper: enumMapper, translator: translator, suffix: ".short"},
+			"LongEnumOrder":  enumSorter{mapper: enumMapper, translator: translator, suffix: ".long"},
+		},
+		map[string]Filter{
+			"BooleanFilter":     booleanFilter{},
+			"StringFilter":      stringFilter{},
+			"StringRegExFilter": regexFilter{},
+			"EnumFilter":        enumFilter{mapper: enumMapper, translator: translator},
+			"NumericFilter":     numericFilter{},
+			"DateFilter":        dateFilter{dateOnly: true},
+			"DateTimeFilter":    dateFilter{},
+		},
+		time.Now,
+	}
+
+	for _, option := range options {
+		option(connector)
+	}
+
+	return connector, nil
+}
+
+func (th Connector) ValidateRequest(request datasource.Request) error {
+	return th.ValidateRequestContext(context.Background(), request)
+}
+
+// ValidateRequestContext validates the request in a single pass. All problems found are
+// returned as datasource.ValidationErrors.
+func (th Connector) ValidateRequestContext(ctx context.Context, request datasource.Request) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var errs datasource.ValidationErrors
+
+	if len(request.Columns()) == 0 {
+		errs.Add(datasource.ValidationInvalidRequest, datasource.CodeNoColumns, "", "no columns selected")
+	}
+
+	schema, err := th.schemaMapper.ResolvedSchema(request.Schema())
+	if err != nil {
+		errs.Add(datasource.ValidationUnknownSchema, datasource.CodeUnknownSchema, "", "unknown schema %s", request.Schema())
+		return errs
+	}
+
+	if _, exists := th.tables[request.Schema()]; !exists {
+		errs.Add(datasource.ValidationUnknownSchema, datasource.CodeNoData, "", "no data for schema %s", request.Schema())
+	}
+
+	if _, err := th.translator.Language(request.Locale()); err != nil {
+		errs.Add(datasource.ValidationUnknownLocale, datasource.CodeUnknownLocale, "", "unknown locale %s", request.Locale())
+	}
+
+	environment, err := th.environment(request.TimeZone(), request.Locale())
+	if err != nil {
+		errs.Add(datasource.ValidationUnknownTimeZone, datasource.CodeUnknownTimeZone, "", "unknown time zone %s", request.TimeZone())
+		environment = dates.NewEnvironment(time.UTC, request.Locale(), th.clock)
+	}
+
+	for _, columnPath := range request.Columns() {
+		column, err := schema.Column(columnPath)
+		if err != nil {
+			errs.Add(datasource.ValidationUnknownColumn, datasource.CodeUnknownColumn, columnPath, "unknown column %s", columnPath)
+			continue
+		}
+
+		if th.filters[column.Filter] == nil {
+			errs.Add(datasource.ValidationUnknownFilter, datasource.CodeUnknownFilter, columnPath,
+				"unknown filter %s on column %s", column.Filter, columnPath)
+		}
+
+		if th.sorters[column.Order] == nil {
+			errs.Add(datasource.ValidationUnknownOrder, datasource.CodeUnknownOrder, columnPath,
+				"unknown order %s on column %s", column.Order, columnPath)
+		}
+	}
+
+	for _, filterGroup := range datasource.FilterGroupsOf(request.FilterExpression()) {
+		columnPath := filterGroup.Path()
+
+		column, err := schema.Column(columnPath)
+		if err != nil {
+			errs.Add(datasource.ValidationUnknownColumn, datasource.CodeUnknownFilterColumn, columnPath,
+				"unknown filter column %s", columnPath)
+			continue
+		}
+
+		columnFilter := th.filters[column.Filter]
+		if columnFilter == nil {
+			errs.Add(datasource.ValidationUnknownFilter, datasource.CodeUnknownFilter, columnPath,
+				"unknown filter %s on column %s", column.Filter, columnPath)
+			continue
+		}
+
+		for _, groupFilter := range filterGroup.Filters() {
+			if !supportsMode(columnFilter, groupFilter.FilterMode()) {
+				errs.Add(datasource.ValidationUnsupportedFilterMode, datasource.CodeUnsupportedFilterMode, columnPath,
+					"unsupported filter mode %s on column %s, supported modes are %s", groupFilter.FilterMode(), columnPath,
+					joinFilterModes(columnFilter.Modes()))
+			}
+
+			values, err := filterValues(groupFilter)
+			if err != nil {
+				errs.Add(datasource.ValidationInvalidValue, datasource.CodeInvalidFilterValue, columnPath,
+					"invalid value %v on column %s: %s", groupFilter.Value(), columnPath, err)
+				continue
+			}
+
+			for _, value := range values {
+				if _, err := parseFilterValue(columnFilter, value, environment); err != nil {
+					errs.Add(datasource.ValidationInvalidValue, datasource.CodeInvalidFilterValue, columnPath,
+						"invalid value %v on column %s: %s", value, columnPath, err)
+				}
+			}
+		}
+	}
+
+	for _, column := range request.Orders() {
+		if _, err := schema.Column(column.Path()); err == config.ErrUnknownColumn {
+			errs.Add(datasource.ValidationUnknownColumn, datasource.CodeUnknownOrderColumn, column.Path(),
+				"unknown order column %s", column.Path())
+		}
+	}
+
+	return errs.Err()
+}
+
+func (th Connector) FetchData(request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	return th.FetchDataContext(context.Background(), request)
+}
+
+func (th Connector) FetchDataContext(ctx context.Context, request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	start := time.Now()
+
+	rows, err := th.FetchRows(ctx, request)
+	if err != nil {
+		return nil, 0, 0, "", err
+	}
+
+	defer rows.Close()
+
+	dataResult := datasource.Result{}
+	for rows.Next() {
+		dataResult = append(dataResult, rows.Row())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, 0, "", err
+	}
+
+	continuation, err := rows.Continuation()
+	if err != nil {
+		return nil, 0, 0, "", err
+	}
+
+	totalCount, filteredCount, err := rows.Counts()
+	if err != nil {
+		return nil, 0, 0, "", err
+	}
+
+	log.WithFields(
+		"time", time.Since(start),
+		"totalCount", totalCount,
+		"filteredCount", filteredCount,
+		"count", len(dataResult),
+	).Info("Data fetched")
+
+	return &dataResult, totalCount, filteredCount, continuation, nil
+}
+
+// FetchRows filters, orders and pages the table of the requested schema. As all data is in
+// memory already, the whole page is prepared before the Rows are returned.
+func (th Connector) FetchRows(ctx context.Context, request datasource.Request) (datasource.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	schema, columns, err := th.resolveRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	matched, err := th.matchingRows(request, schema, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	th.sortRows(matched, request.Orders(), schema, request.Locale())
+
+	limit := uint64(len(matched))
+	if request.Limit() > 0 {
+		limit = request.Limit()
+	}
+
+	// Like the sql data source, an offset is only applied to limited requests
+	var offset uint64
+	if request.Limit() > 0 {
+		offset = request.Offset()
+
+		if request.Continuation() != "" {
+			if offset, err = decodeContinuation(request.Continuation()); err != nil {
+				return nil, err
+			}
+		}
+	} else if request.Offset() > 0 {
+		log.WithField("offset", request.Offset()).Warn("Ignoring offset for request without limit")
+	}
+
+	resultRows := &Rows{
+		columns:       request.Columns(),
+		index:         -1,
+		totalCount:    uint64(len(th.tables[request.Schema()])),
+		filteredCount: uint64(len(matched)),
+	}
+
+	if offset < uint64(len(matched)) {
+		end := offset + limit
+		if end > uint64(len(matched)) {
+			end = uint64(len(matched))
+		}
+
+		resultRows.rows = matched[offset:end]
+
+		if request.Limit() > 0 && end < uint64(len(matched)) {
+			resultRows.continuation = encodeContinuation(end)
+		}
+	}
+
+	return resultRows, nil
+}
+
+// Resolves the schema and the selected columns of a request.
+func (th Connector) resolveRequest(request datasource.Request) (config.ResolvedTableSchema, []config.TableSchemaColumn, error) {
+	schema, err := th.schemaMapper.ResolvedSchema(request.Schema())
+	if err != nil {
+		return config.ResolvedTableSchema{}, nil, err
+	}
+
+	columns := make([]config.TableSchemaColumn, len(request.Columns()))
+	for i, columnPath := range request.Columns() {
+		columns[i], err = schema.Column(columnPath)
+		if err != nil {
+			return config.ResolvedTableSchema{}, nil, err
+		}
+	}
+
+	return schema, columns, nil
+}
+
+// Returns all rows of the requested table, which match the filters and the global search.
+// The global search is applied to the selected columns.
+func (th Connector) matchingRows(request datasource.Request, schema config.ResolvedTableSchema,
+	columns []config.TableSchemaColumn) ([]map[string]interface{}, error) {
+	table, exists := th.tables[request.Schema()]
+	if !exists {
+		return nil, fmt.Errorf("no data for schema %s", request.Schema())
+	}
+
+	environment, err := th.environment(request.TimeZone(), request.Locale())
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %s", request.TimeZone())
+	}
+
+	filterPredicate, err := th.compileFilter(request.FilterExpression(), schema, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	searchPredicate := th.compileSearch(request.GlobalSearch(), columns, request.Locale())
+
+	var matched []map[string]interface{}
+	for _, row := range table {
+		matches, err := filterPredicate(row)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			if matches, err = searchPredicate(row); err != nil {
+				return nil, err
+			}
+		}
+
+		if matches {
+			matched = append(matched, row)
+		}
+	}
+
+	return matched, nil
+}
+
+func joinFilterModes(filterModes []tableaux.FilterMode) string {
+	names := make([]string, len(filterModes))
+	for i, filterMode := range filterModes {
+		names[i] = string(filterMode)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Creates the environment, which the filter values of a request are interpreted in. An empty
+// time zone stands for UTC.
+func (th Connector) environment(timeZone, locale string) (dates.Environment, error) {
+	location := time.UTC
+	if timeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(timeZone); err != nil {
+			return dates.Environment{}, err
+		}
+	}
+
+	return dates.NewEnvironment(location, locale, th.clock), nil
+}
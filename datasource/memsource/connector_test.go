@@ -0,0 +1,418 @@
+package memsource
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+)
+
+type person struct {
+	ID       int64   `tableaux:"person_id"`
+	Name     string  `tableaux:"person_name"`
+	Age      *int    `tableaux:"person_age"`
+	Active   bool    `tableaux:"person_active"`
+	Country  string  `tableaux:"person_country"`
+	Birthday string  `tableaux:"person_birthday"`
+	Salary   float64 `tableaux:"-"`
+}
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func newTestConnector(t *testing.T, options ...Option) datasource.Connector {
+	enumMapper, err := config.NewEnumMapperFromFolder(filepath.Join("testfiles", "enum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translator, err := config.NewTranslatorFromFolder(filepath.Join("testfiles", "i18n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaMapper, err := config.NewSchemaMapperFromFolder(filepath.Join("testfiles", "schema"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	persons, err := TableFromStructs([]person{
+		{1, "Alice", intPointer(30), true, "DE", "1988-01-05", 0},
+		{2, "Bob", intPointer(25), false, "CH", "1993-07-12", 0},
+		{3, "Carol", nil, true, "AT", "1979-11-30", 0},
+		{4, "Dave", intPointer(25), true, "DE", "2001-02-28", 0},
+		{5, "Al_ex", intPointer(41), false, "XX", "1977-03-15", 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connector, err := NewConnector(map[string]Table{"persons": persons}, enumMapper, translator, schemaMapper, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return connector
+}
+
+// Fetches the ids of all rows of the request.
+func fetchIDs(t *testing.T, connector datasource.Connector, request datasource.Request) []int64 {
+	result, _, _, _, err := connector.FetchData(request)
+	if err != nil {
+		t.Fatalf("FetchData failed: %s", err)
+	}
+
+	ids := []int64{}
+	for _, row := range *result {
+		ids = append(ids, row["person_id"].(int64))
+	}
+
+	return ids
+}
+
+func TestFetchDataFilters(t *testing.T) {
+	connector := newTestConnector(t)
+
+	tables := []struct {
+		name  string
+		where datasource.FilterExpression
+		ids   []int64
+	}{
+		{"equals", datasource.NewSimpleFilterGroup("person_name", tableaux.FilterEquals, []interface{}{"Bob", "Dave"}), []int64{2, 4}},
+		{"not equals", datasource.NewSimpleFilterGroup("person_country", tableaux.FilterNotEquals, []interface{}{"DE"}), []int64{2, 3, 5}},
+		{"greater", datasource.NewSimpleFilterGroup("person_age", tableaux.FilterGreater, []interface{}{int64(25)}), []int64{1, 5}},
+		{"greater equals", datasource.NewSimpleFilterGroup("person_age", tableaux.FilterGreaterEquals, []interface{}{"30"}), []int64{1, 5}},
+		{"lesser", datasource.NewSimpleFilterGroup("person_age", tableaux.FilterLesser, []interface{}{int64(30)}), []int64{2, 4}},
+		{"lesser equals", datasource.NewSimpleFilterGroup("person_birthday", tableaux.FilterLesserEquals, []interface{}{"1979-11-30"}), []int64{3, 5}},
+		{"boolean", datasource.NewSimpleFilterGroup("person_active", tableaux.FilterEquals, []interface{}{"false"}), []int64{2, 5}},
+		{"pattern", datasource.NewSimpleFilterGroup("person_name", tableaux.FilterEquals, []interface{}{"Al.*"}), []int64{1, 5}},
+		{"negated pattern", datasource.NewSimpleFilterGroup("person_name", tableaux.FilterNotEquals, []interface{}{".*a.*"}), []int64{1, 2, 5}},
+		{"tree", datasource.NewOrExpression(
+			datasource.NewSimpleFilterGroup("person_country", tableaux.FilterEquals, []interface{}{"CH"}),
+			datasource.NewAndExpression(
+				datasource.NewSimpleFilterGroup("person_active", tableaux.FilterEquals, []interface{}{true}),
+				datasource.NewNotExpression(datasource.NewSimpleFilterGroup("person_country", tableaux.FilterEquals, []interface{}{"DE"})),
+			),
+		), []int64{2, 3}},
+	}
+
+	for _, table := range tables {
+		request := datasource.NewRequestBuilder("persons").Columns("person_id").Where(table.where).Locale("en").Build()
+
+		if ids := fetchIDs(t, connector, request); !reflect.DeepEqual(ids, table.ids) {
+			t.Errorf("Filter %s was incorrect, got: %v, want: %v.", table.name, ids, table.ids)
+		}
+	}
+}
+
+func TestFetchDataFilterModes(t *testing.T) {
+	connector := newTestConnector(t)
+
+	tables := []struct {
+		path       string
+		filterMode tableaux.FilterMode
+		value      interface{}
+		ids        []int64
+	}{
+		{"person_age", tableaux.FilterIn, []interface{}{int64(25), "41"}, []int64{2, 4, 5}},
+		{"person_country", tableaux.FilterIn, []interface{}{"AT", "CH"}, []int64{2, 3}},
+		{"person_birthday", tableaux.FilterIn, []interface{}{"1979-11-30", "2001-02-28T12:00:00"}, []int64{3, 4}},
+		{"person_age", tableaux.FilterBetween, []interface{}{int64(25), int64(30)}, []int64{1, 2, 4}},
+		{"person_name", tableaux.FilterBetween, []interface{}{"B", "D"}, []int64{2, 3}},
+		{"person_birthday", tableaux.FilterBetween, []interface{}{"1979-11-30", "1988-01-05"}, []int64{1, 3}},
+		{"person_age", tableaux.FilterIsNull, nil, []int64{3}},
+		{"person_age", tableaux.FilterIsNotNull, nil, []int64{1, 2, 4, 5}},
+		{"person_country", tableaux.FilterIsNotNull, nil, []int64{1, 2, 3, 4, 5}},
+		{"person_name", tableaux.FilterContains, "a", []int64{3, 4}},
+		{"person_name", tableaux.FilterContains, "_", []int64{5}},
+		{"person_name", tableaux.FilterContains, ".*", []int64{}},
+		{"person_name", tableaux.FilterStartsWith, "Al", []int64{1, 5}},
+		{"person_name", tableaux.FilterEndsWith, "e", []int64{1, 4}},
+	}
+
+	for _, table := range tables {
+		request := datasource.NewRequestBuilder("persons").
+			Columns("person_id").
+			Filters(datasource.NewSimpleFilterGroup(table.path, table.filterMode, []interface{}{table.value})).
+			Locale("en").
+			Build()
+
+		if ids := fetchIDs(t, connector, request); !reflect.DeepEqual(ids, table.ids) {
+			t.Errorf("Filter %s %s %v was incorrect, got: %v, want: %v.", table.path, table.filterMode, table.value, ids, table.ids)
+		}
+	}
+
+	// Null checks are OR'ed with the other filters of their group
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(datasource.NewFilterGroup("person_age", []datasource.Filter{
+			datasource.NewFilter(tableaux.FilterIsNull, nil),
+			datasource.NewFilter(tableaux.FilterGreater, int64(30)),
+		})).
+		Locale("en").
+		Build()
+
+	if ids, want := fetchIDs(t, connector, request), []int64{3, 5}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Filter group with null check was incorrect, got: %v, want: %v.", ids, want)
+	}
+}
+
+func TestFetchDataDates(t *testing.T) {
+	// Already the first of March in Berlin
+	connector := newTestConnector(t, WithClock(func() time.Time {
+		return time.Date(2001, time.February, 28, 23, 30, 0, 0, time.UTC)
+	}))
+
+	tables := []struct {
+		filterMode tableaux.FilterMode
+		value      interface{}
+		timeZone   string
+		ids        []int64
+	}{
+		{tableaux.FilterEquals, "1988-01-05", "", []int64{1}},
+		{tableaux.FilterEquals, "1988-01-05T10:00:00", "", []int64{1}},
+		{tableaux.FilterEquals, "1988-01-05T23:30:00Z", "Europe/Berlin", []int64{}},
+		{tableaux.FilterNotEquals, "1988-01-05", "", []int64{2, 3, 4, 5}},
+		{tableaux.FilterGreater, "1993-07-12", "", []int64{4}},
+		{tableaux.FilterGreaterEquals, "1993-07-12", "", []int64{2, 4}},
+		{tableaux.FilterLesser, "1979-11-30", "", []int64{5}},
+		{tableaux.FilterEquals, "today", "", []int64{4}},
+		{tableaux.FilterEquals, "today", "Europe/Berlin", []int64{}},
+		{tableaux.FilterEquals, "last 7 days", "Europe/Berlin", []int64{4}},
+		{tableaux.FilterLesser, "this year", "", []int64{1, 2, 3, 5}},
+	}
+
+	for _, table := range tables {
+		request := datasource.NewRequestBuilder("persons").
+			Columns("person_id").
+			Filters(datasource.NewSimpleFilterGroup("person_birthday", table.filterMode, []interface{}{table.value})).
+			Locale("en").
+			TimeZone(table.timeZone).
+			Build()
+
+		if ids := fetchIDs(t, connector, request); !reflect.DeepEqual(ids, table.ids) {
+			t.Errorf("Filter %s %v in %s was incorrect, got: %v, want: %v.", table.filterMode, table.value, table.timeZone, ids, table.ids)
+		}
+	}
+}
+
+func TestDateTimeFilter(t *testing.T) {
+	dateTimeFilter := dateFilter{}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second of March lasts from 23:00 to 23:00 UTC in Berlin
+	valueRange, err := dateTimeFilter.Range("2024-03-02", dates.NewEnvironment(berlin, "de", nil))
+	if err != nil {
+		t.Fatalf("Range failed: %s", err)
+	}
+
+	tables := []struct {
+		value   interface{}
+		matches bool
+	}{
+		{time.Date(2024, time.March, 1, 22, 59, 59, 0, time.UTC), false},
+		{time.Date(2024, time.March, 1, 23, 0, 0, 0, time.UTC), true},
+		{"2024-03-02 22:59:59", true},
+		{"2024-03-03T00:00:00+01:00", false},
+		{nil, false},
+	}
+
+	for _, table := range tables {
+		matches, err := dateTimeFilter.Match(table.value, valueRange, tableaux.FilterEquals)
+		if err != nil {
+			t.Errorf("Match(%v) failed: %s", table.value, err)
+		} else if matches != table.matches {
+			t.Errorf("Match(%v) was incorrect, got: %t, want: %t.", table.value, matches, table.matches)
+		}
+	}
+}
+
+func TestFetchDataSearch(t *testing.T) {
+	connector := newTestConnector(t)
+
+	tables := []struct {
+		term string
+		ids  []int64
+	}{
+		{"al", []int64{1, 5}},
+		{"_", []int64{5}},
+		{"austria", []int64{3}},
+		{"25", []int64{2, 4}},
+		{"nobody", []int64{}},
+	}
+
+	for _, table := range tables {
+		request := datasource.NewRequestBuilder("persons").
+			Columns("person_id", "person_name", "person_age", "person_country").
+			GlobalSearch(table.term).
+			Locale("en").
+			Build()
+
+		if ids := fetchIDs(t, connector, request); !reflect.DeepEqual(ids, table.ids) {
+			t.Errorf("Search for %s was incorrect, got: %v, want: %v.", table.term, ids, table.ids)
+		}
+	}
+}
+
+func TestFetchDataOrders(t *testing.T) {
+	connector := newTestConnector(t)
+
+	tables := []struct {
+		name   string
+		orders []datasource.Order
+		ids    []int64
+	}{
+		{"none", nil, []int64{1, 2, 3, 4, 5}},
+		{"descending with nil", []datasource.Order{datasource.NewOrder("person_age", tableaux.OrderDesc, nil)}, []int64{5, 1, 2, 4, 3}},
+		{"translated enum", []datasource.Order{datasource.NewOrder("person_country", tableaux.OrderAsc, nil)}, []int64{5, 3, 1, 4, 2}},
+		{"sort keys", []datasource.Order{datasource.NewOrder("person_country", tableaux.OrderAsc, []interface{}{"CH", "DE", "AT"})}, []int64{5, 2, 1, 4, 3}},
+		{"multiple", []datasource.Order{
+			datasource.NewOrder("person_age", tableaux.OrderAsc, nil),
+			datasource.NewOrder("person_name", tableaux.OrderDesc, nil),
+		}, []int64{3, 4, 2, 1, 5}},
+	}
+
+	for _, table := range tables {
+		request := datasource.NewRequestBuilder("persons").Columns("person_id").Orders(table.orders...).Locale("en").Build()
+
+		if ids := fetchIDs(t, connector, request); !reflect.DeepEqual(ids, table.ids) {
+			t.Errorf("Order %s was incorrect, got: %v, want: %v.", table.name, ids, table.ids)
+		}
+	}
+}
+
+func TestFetchDataPaging(t *testing.T) {
+	connector := newTestConnector(t)
+
+	builder := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(datasource.NewSimpleFilterGroup("person_id", tableaux.FilterGreater, []interface{}{int64(1)})).
+		Limit(2).
+		Locale("en")
+
+	if ids := fetchIDs(t, connector, builder.Offset(1).Build()); !reflect.DeepEqual(ids, []int64{3, 4}) {
+		t.Errorf("Offset was incorrect, got: %v, want: %v.", ids, []int64{3, 4})
+	}
+
+	var pages [][]int64
+	continuation := ""
+	for {
+		result, totalCount, filteredCount, next, err := connector.FetchData(builder.Offset(0).Continuation(continuation).Build())
+		if err != nil {
+			t.Fatalf("FetchData failed: %s", err)
+		}
+
+		if totalCount != 5 || filteredCount != 4 {
+			t.Errorf("Counts were incorrect, got: %d/%d, want: 5/4.", totalCount, filteredCount)
+		}
+
+		var page []int64
+		for _, row := range *result {
+			page = append(page, row["person_id"].(int64))
+		}
+		pages = append(pages, page)
+
+		if next == "" {
+			break
+		}
+		continuation = next
+	}
+
+	if want := [][]int64{{2, 3}, {4, 5}}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("Pages were incorrect, got: %v, want: %v.", pages, want)
+	}
+
+	if _, _, _, _, err := connector.FetchData(builder.Continuation("invalid").Build()); err != ErrInvalidContinuation {
+		t.Errorf("Invalid continuation was accepted, got: %v, want: %v.", err, ErrInvalidContinuation)
+	}
+}
+
+func TestFetchFacets(t *testing.T) {
+	connector := newTestConnector(t)
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(datasource.NewSimpleFilterGroup("person_id", tableaux.FilterLesser, []interface{}{int64(5)})).
+		Locale("en").
+		Build()
+
+	result, err := connector.FetchFacets(context.Background(),
+		datasource.NewFacetRequest(request, "person_country", tableaux.FacetOrderCount, tableaux.OrderDesc, 0))
+	if err != nil {
+		t.Fatalf("FetchFacets failed: %s", err)
+	}
+
+	want := datasource.FacetResult{
+		{Value: "DE", Count: 2, TranslationKey: "enum.country.de"},
+		{Value: "AT", Count: 1, TranslationKey: "enum.country.at"},
+		{Value: "CH", Count: 1, TranslationKey: "enum.country.ch"},
+	}
+
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("Facets were incorrect, got: %+v, want: %+v.", *result, want)
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	connector := newTestConnector(t)
+
+	tables := []struct {
+		request datasource.Request
+		codes   []datasource.ValidationCode
+	}{
+		{datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").Build(), nil},
+		{datasource.NewRequestBuilder("persons").Locale("en").Build(), []datasource.ValidationCode{datasource.CodeNoColumns}},
+		{datasource.NewRequestBuilder("unknown").Columns("person_id").Locale("en").Build(), []datasource.ValidationCode{datasource.CodeUnknownSchema}},
+		{datasource.NewRequestBuilder("persons").Columns("person_unknown").Locale("en").Build(), []datasource.ValidationCode{datasource.CodeUnknownColumn}},
+		{datasource.NewRequestBuilder("persons").Columns("person_id").Locale("xx").Build(), []datasource.ValidationCode{datasource.CodeUnknownLocale}},
+		{datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").TimeZone("Mars/Olympus").
+			Filters(datasource.NewSimpleFilterGroup("person_birthday", tableaux.FilterEquals, []interface{}{"last 0 days"})).Build(),
+			[]datasource.ValidationCode{datasource.CodeUnknownTimeZone, datasource.CodeInvalidFilterValue}},
+		{datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").
+			Filters(datasource.NewSimpleFilterGroup("person_country", tableaux.FilterGreater, []interface{}{"DE"})).Build(),
+			[]datasource.ValidationCode{datasource.CodeUnsupportedFilterMode}},
+		{datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").
+			Filters(
+				datasource.NewSimpleFilterGroup("person_age", tableaux.FilterBetween, []interface{}{[]interface{}{int64(25)}}),
+				datasource.NewSimpleFilterGroup("person_age", tableaux.FilterIn, []interface{}{[]interface{}{int64(25), "old"}}),
+			).Build(),
+			[]datasource.ValidationCode{datasource.CodeInvalidFilterValue, datasource.CodeInvalidFilterValue}},
+		{datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").
+			Orders(datasource.NewOrder("person_unknown", tableaux.OrderAsc, nil)).Build(), []datasource.ValidationCode{datasource.CodeUnknownOrderColumn}},
+		{datasource.NewRequestBuilder("persons").Columns("person_id", "person_unknown").Locale("xx").
+			Filters(datasource.NewSimpleFilterGroup("person_age", tableaux.FilterEquals, []interface{}{true})).Build(),
+			[]datasource.ValidationCode{datasource.CodeUnknownLocale, datasource.CodeUnknownColumn, datasource.CodeInvalidFilterValue}},
+	}
+
+	for _, table := range tables {
+		err := connector.ValidateRequest(table.request)
+
+		var codes []datasource.ValidationCode
+		if err != nil {
+			errs, isValidationErrors := err.(datasource.ValidationErrors)
+			if !isValidationErrors {
+				t.Errorf("ValidateRequest(%+v) returned untyped error: %v", table.request, err)
+				continue
+			}
+
+			for _, validationErr := range errs {
+				codes = append(codes, validationErr.Code)
+			}
+		}
+
+		if !reflect.DeepEqual(codes, table.codes) {
+			t.Errorf("ValidateRequest(%+v) was incorrect, got: %v, want: %v.", table.request, codes, table.codes)
+		}
+	}
+}
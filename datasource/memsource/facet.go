@@ -0,0 +1,112 @@
+package memsource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/datasource"
+)
+
+// FetchFacets returns the distinct values of a single path, and how many rows have each value.
+// Values of enum columns are returned with their translation key.
+func (th Connector) FetchFacets(ctx context.Context, request datasource.FacetRequest) (*datasource.FacetResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	schema, columns, err := th.resolveRequest(request.Request())
+	if err != nil {
+		return nil, err
+	}
+
+	column, err := schema.Column(request.Path())
+	if err != nil {
+		return nil, fmt.Errorf("unknown facet column %s", request.Path())
+	}
+
+	if request.OrderBy() != tableaux.FacetOrderCount && request.OrderBy() != tableaux.FacetOrderValue {
+		return nil, fmt.Errorf("unknown facet order %s", request.OrderBy())
+	}
+
+	matched, err := th.matchingRows(request.Request(), schema, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the rows by sorting them by value, so equal values are adjacent
+	sort.SliceStable(matched, func(i, j int) bool {
+		return compareValues(matched[i][column.Path], matched[j][column.Path]) < 0
+	})
+
+	facetResult := datasource.FacetResult{}
+	for _, row := range matched {
+		last := len(facetResult) - 1
+		if last >= 0 && compareValues(facetResult[last].Value, row[column.Path]) == 0 {
+			facetResult[last].Count++
+			continue
+		}
+
+		facetResult = append(facetResult, datasource.FacetValue{
+			Value: row[column.Path],
+			Count: 1,
+		})
+	}
+
+	// Values are ordered by the sorter of the column (e.g. by translation for enums), and
+	// also break ties when ordering by count, so the result is stable
+	valueDirection := request.Direction()
+	if request.OrderBy() == tableaux.FacetOrderCount {
+		valueDirection = tableaux.OrderAsc
+	}
+
+	valueOrder := datasource.NewOrder(column.Path, valueDirection, nil)
+	sortKeys := make([]interface{}, len(facetResult))
+	for i, facetValue := range facetResult {
+		sortKeys[i] = th.sortKey(facetValue.Value, valueOrder, schema, request.Request().Locale())
+	}
+
+	indices := make([]int, len(facetResult))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	sort.SliceStable(indices, func(a, b int) bool {
+		if request.OrderBy() == tableaux.FacetOrderCount {
+			aCount, bCount := facetResult[indices[a]].Count, facetResult[indices[b]].Count
+			if aCount != bCount {
+				return (aCount < bCount) != (request.Direction() == tableaux.OrderDesc)
+			}
+		}
+
+		comparison := compareValues(sortKeys[indices[a]], sortKeys[indices[b]])
+		if valueDirection == tableaux.OrderDesc {
+			comparison = -comparison
+		}
+
+		return comparison < 0
+	})
+
+	sorted := make(datasource.FacetResult, len(facetResult))
+	for i, index := range indices {
+		sorted[i] = facetResult[index]
+	}
+
+	facetResult = sorted
+
+	if request.Limit() > 0 && uint64(len(facetResult)) > request.Limit() {
+		facetResult = facetResult[:request.Limit()]
+	}
+
+	// Only enum columns have translation keys
+	if enum, err := th.enumMapper.Enum(column.Type); err == nil {
+		for i, facetValue := range facetResult {
+			if key, isKey := normalizeValue(facetValue.Value).(string); isKey {
+				facetResult[i].TranslationKey, _ = enum.TranslationKey(key)
+			}
+		}
+	}
+
+	return &facetResult, nil
+}
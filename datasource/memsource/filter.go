@@ -0,0 +1,603 @@
+package memsource
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+)
+
+// Filter is the in-memory counterpart of the sql filters. It converts raw filter values,
+// and matches them against the values of a column.
+type Filter interface {
+	// ParseValue converts a raw filter value into the value to match column values against.
+	ParseValue(value interface{}) (interface{}, error)
+
+	// Match checks if the column value matches the parsed filter value in the given FilterMode,
+	// which is one of the comparison modes. Like in SQL, a nil column value never matches.
+	Match(value, filterValue interface{}, filterMode tableaux.FilterMode) (bool, error)
+
+	// Modes returns the filter modes, which the filter supports. IN and BETWEEN are matched via
+	// the comparison modes, null checks and substring modes by the Connector itself.
+	Modes() []tableaux.FilterMode
+}
+
+// The filter modes of columns, which are compared by value rather than as text.
+var valueModes = []tableaux.FilterMode{
+	tableaux.FilterEquals, tableaux.FilterNotEquals, tableaux.FilterGreater, tableaux.FilterGreaterEquals,
+	tableaux.FilterLesser, tableaux.FilterLesserEquals, tableaux.FilterIn, tableaux.FilterBetween,
+	tableaux.FilterIsNull, tableaux.FilterIsNotNull,
+}
+
+// The filter modes of enums. Enum keys have no meaningful order, and are not matched as text.
+var enumModes = []tableaux.FilterMode{
+	tableaux.FilterEquals, tableaux.FilterNotEquals, tableaux.FilterIn, tableaux.FilterIsNull, tableaux.FilterIsNotNull,
+}
+
+// Checks if the filter supports the FilterMode.
+func supportsMode(columnFilter Filter, filterMode tableaux.FilterMode) bool {
+	for _, supportedMode := range columnFilter.Modes() {
+		if supportedMode == filterMode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RangeFilter is implemented by filters, of which each value matches a range of column values,
+// rather than a single one - e.g. a date, which matches the whole day. Like for the sql filters,
+// the filter modes are applied to the range.
+type RangeFilter interface {
+	Filter
+
+	// Range converts a raw filter value into the range of column values it matches, within the
+	// environment of the request.
+	Range(value interface{}, environment dates.Environment) (Range, error)
+}
+
+// Range is the half-open range [From, To) of column values, which a filter value matches.
+type Range struct {
+	From interface{}
+	To   interface{}
+}
+
+// Searcher is implemented by filters which can take part in the global search.
+type Searcher interface {
+	// Search checks if the column value matches the search term.
+	Search(term string, value interface{}, column config.TableSchemaColumn, locale string) bool
+}
+
+// Matches a column value by comparing it to the filter value.
+func matchComparison(value, filterValue interface{}, filterMode tableaux.FilterMode) (bool, error) {
+	if normalizeValue(value) == nil {
+		return false, nil
+	}
+
+	comparison := compareValues(value, filterValue)
+
+	switch filterMode {
+	case tableaux.FilterEquals:
+		return comparison == 0, nil
+	case tableaux.FilterNotEquals:
+		return comparison != 0, nil
+	case tableaux.FilterGreater:
+		return comparison > 0, nil
+	case tableaux.FilterGreaterEquals:
+		return comparison >= 0, nil
+	case tableaux.FilterLesser:
+		return comparison < 0, nil
+	case tableaux.FilterLesserEquals:
+		return comparison <= 0, nil
+	default:
+		return false, fmt.Errorf("unknown filter mode %s", filterMode)
+	}
+}
+
+// Matches a column value by comparing it to the range of the filter value. Values within the
+// range are equal to it, greater values lie after it, and lesser values before it.
+func matchRange(value interface{}, valueRange Range, filterMode tableaux.FilterMode) (bool, error) {
+	if normalizeValue(value) == nil {
+		return false, nil
+	}
+
+	from, to := compareValues(value, valueRange.From), compareValues(value, valueRange.To)
+
+	switch filterMode {
+	case tableaux.FilterEquals:
+		return from >= 0 && to < 0, nil
+	case tableaux.FilterNotEquals:
+		return from < 0 || to >= 0, nil
+	case tableaux.FilterGreater:
+		return to >= 0, nil
+	case tableaux.FilterGreaterEquals:
+		return from >= 0, nil
+	case tableaux.FilterLesser:
+		return from < 0, nil
+	case tableaux.FilterLesserEquals:
+		return to < 0, nil
+	default:
+		return false, fmt.Errorf("unknown filter mode %s", filterMode)
+	}
+}
+
+// Matches a column value, which must be a string, against the term of a substring mode. Like
+// patterns, terms are matched case sensitive.
+func matchSubstring(value interface{}, term string, filterMode tableaux.FilterMode) bool {
+	stringValue, isString := normalizeValue(value).(string)
+	if !isString {
+		return false
+	}
+
+	switch filterMode {
+	case tableaux.FilterStartsWith:
+		return strings.HasPrefix(stringValue, term)
+	case tableaux.FilterEndsWith:
+		return strings.HasSuffix(stringValue, term)
+	default:
+		return strings.Contains(stringValue, term)
+	}
+}
+
+// Checks if the value is a string, which contains the search term (case insensitive).
+func containsTerm(value interface{}, term string) bool {
+	stringValue, isString := normalizeValue(value).(string)
+	return isString && strings.Contains(strings.ToLower(stringValue), strings.ToLower(term))
+}
+
+// stringFilter filters plain strings.
+type stringFilter struct {
+}
+
+func (filter stringFilter) ParseValue(value interface{}) (interface{}, error) {
+	stringVal, canCast := value.(string)
+	if canCast {
+		return stringVal, nil
+	}
+
+	return nil, fmt.Errorf("cannot parse value %v as string", value)
+}
+
+func (filter stringFilter) Match(value, filterValue interface{}, filterMode tableaux.FilterMode) (bool, error) {
+	return matchComparison(value, filterValue, filterMode)
+}
+
+// Modes supports all filter modes.
+func (filter stringFilter) Modes() []tableaux.FilterMode {
+	return tableaux.FilterModes()
+}
+
+// Search matches string columns, which contain the term.
+func (filter stringFilter) Search(term string, value interface{}, column config.TableSchemaColumn, _ string) bool {
+	return strings.ToLower(column.Type) == "string" && containsTerm(value, term)
+}
+
+// regexFilter filters strings, of which ".*" in the filter value matches any sequence of
+// characters for equality. Matching patterns is case sensitive.
+type regexFilter struct {
+	stringFilter
+}
+
+func (filter regexFilter) Match(value, filterValue interface{}, filterMode tableaux.FilterMode) (bool, error) {
+	pattern := filterValue.(string)
+	if !strings.Contains(pattern, ".*") || (filterMode != tableaux.FilterEquals && filterMode != tableaux.FilterNotEquals) {
+		return matchComparison(value, filterValue, filterMode)
+	}
+
+	stringValue, isString := normalizeValue(value).(string)
+	if !isString {
+		return false, nil
+	}
+
+	quoted := strings.Split(pattern, ".*")
+	for i, part := range quoted {
+		quoted[i] = regexp.QuoteMeta(part)
+	}
+
+	matched, err := regexp.MatchString("^"+strings.Join(quoted, ".*")+"$", stringValue)
+	if err != nil {
+		return false, err
+	}
+
+	return matched == (filterMode == tableaux.FilterEquals), nil
+}
+
+// numericFilter filters integral numbers.
+type numericFilter struct {
+}
+
+func (filter numericFilter) ParseValue(value interface{}) (interface{}, error) {
+	switch converted := normalizeValue(value).(type) {
+	case int64, uint64:
+		return converted, nil
+	case string:
+		return strconv.ParseInt(converted, 10, 64)
+	default:
+		return nil, fmt.Errorf("cannot parse value %v as number", value)
+	}
+}
+
+func (filter numericFilter) Match(value, filterValue interface{}, filterMode tableaux.FilterMode) (bool, error) {
+	return matchComparison(value, filterValue, filterMode)
+}
+
+// Modes supports all filter modes, except for matching substrings.
+func (filter numericFilter) Modes() []tableaux.FilterMode {
+	return valueModes
+}
+
+// Search matches the column value, if the term is a number equal to it.
+func (filter numericFilter) Search(term string, value interface{}, _ config.TableSchemaColumn, _ string) bool {
+	intValue, err := strconv.ParseInt(term, 10, 64)
+	if err != nil || normalizeValue(value) == nil {
+		return false
+	}
+
+	return compareValues(value, intValue) == 0
+}
+
+// booleanFilter filters booleans. Strings are parsed as true if they are "1" or "true".
+type booleanFilter struct {
+}
+
+func (filter booleanFilter) ParseValue(value interface{}) (interface{}, error) {
+	boolean, canCast := value.(bool)
+	if canCast {
+		return boolean, nil
+	}
+
+	booleanString, canCast := value.(string)
+	if canCast {
+		return booleanString == "1" || strings.ToLower(booleanString) == "true", nil
+	}
+
+	return nil, fmt.Errorf("cannot parse value %v as boolean", value)
+}
+
+func (filter booleanFilter) Match(value, filterValue interface{}, filterMode tableaux.FilterMode) (bool, error) {
+	return matchComparison(value, filterValue, filterMode)
+}
+
+// Modes supports all filter modes, except for matching substrings.
+func (filter booleanFilter) Modes() []tableaux.FilterMode {
+	return valueModes
+}
+
+// dateFilter filters date or date time columns. Values are resolved as of dates.DateRange or
+// dates.DateTimeRange, so e.g. a date matches its whole day in the time zone of the request, and
+// a relative date like "last 7 days" its whole span of days. Column values are compared as points
+// in time, of which only the calendar date is considered for date columns.
+type dateFilter struct {
+	dateOnly bool
+}
+
+// ParseValue converts the value into its range in UTC.
+func (filter dateFilter) ParseValue(value interface{}) (interface{}, error) {
+	return filter.Range(value, dates.NewEnvironment(time.UTC, "", nil))
+}
+
+func (filter dateFilter) Range(value interface{}, environment dates.Environment) (Range, error) {
+	if !filter.dateOnly {
+		from, to, err := dates.DateTimeRange(value, environment)
+		if err != nil {
+			return Range{}, err
+		}
+
+		return Range{From: from, To: to}, nil
+	}
+
+	from, to, err := dates.DateRange(value, environment)
+	if err != nil {
+		return Range{}, err
+	}
+
+	return Range{From: calendarDate(from), To: calendarDate(to)}, nil
+}
+
+func (filter dateFilter) Match(value, filterValue interface{}, filterMode tableaux.FilterMode) (bool, error) {
+	if day, isTime := normalizeValue(value).(time.Time); isTime && filter.dateOnly {
+		value = calendarDate(day)
+	}
+
+	return matchRange(value, filterValue.(Range), filterMode)
+}
+
+// Modes supports all filter modes, except for matching substrings.
+func (filter dateFilter) Modes() []tableaux.FilterMode {
+	return valueModes
+}
+
+// Returns the calendar date of a time as midnight UTC, which is how date columns are compared.
+func calendarDate(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// enumFilter filters enum columns by their enum keys.
+type enumFilter struct {
+	stringFilter
+
+	mapper     config.EnumMapper
+	translator config.Translator
+}
+
+// Modes only supports equality, IN and null checks, as enum keys have no meaningful order.
+func (filter enumFilter) Modes() []tableaux.FilterMode {
+	return enumModes
+}
+
+// Search matches enum keys, of which either the key itself or its translation in the
+// given locale contains the search term (case insensitive).
+func (filter enumFilter) Search(term string, value interface{}, column config.TableSchemaColumn, locale string) bool {
+	key, isKey := normalizeValue(value).(string)
+	if !isKey {
+		return false
+	}
+
+	if containsTerm(key, term) {
+		return true
+	}
+
+	translationKey, err := filter.mapper.TranslationKeyInEnum(column.Type, key)
+	if err != nil {
+		return false
+	}
+
+	translation, err := filter.translator.Translate(locale, translationKey)
+	return err == nil && containsTerm(translation, term)
+}
+
+// predicate checks if a single row matches.
+type predicate func(row map[string]interface{}) (bool, error)
+
+func matchAll(_ map[string]interface{}) (bool, error) {
+	return true, nil
+}
+
+// Parses a filter value. Values of a RangeFilter are converted into their range within the
+// environment of the request.
+func parseFilterValue(columnFilter Filter, value interface{}, environment dates.Environment) (interface{}, error) {
+	if rangeFilter, isRangeFilter := columnFilter.(RangeFilter); isRangeFilter {
+		return rangeFilter.Range(value, environment)
+	}
+
+	return columnFilter.ParseValue(value)
+}
+
+// Compiles a FilterExpression tree into a predicate. All filter values are parsed upfront within
+// the environment of the request, so invalid values are reported before any row is matched.
+func (th Connector) compileFilter(filterExpression datasource.FilterExpression, schema config.ResolvedTableSchema,
+	environment dates.Environment) (predicate, error) {
+	switch node := filterExpression.(type) {
+	case nil:
+		return matchAll, nil
+	case datasource.FilterGroup:
+		return th.compileFilterGroup(node, schema, environment)
+	case datasource.AndExpression:
+		predicates, err := th.compileFilters(node.Expressions(), schema, environment)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(row map[string]interface{}) (bool, error) {
+			for _, nodePredicate := range predicates {
+				if matches, err := nodePredicate(row); err != nil || !matches {
+					return false, err
+				}
+			}
+
+			return true, nil
+		}, nil
+	case datasource.OrExpression:
+		predicates, err := th.compileFilters(node.Expressions(), schema, environment)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(row map[string]interface{}) (bool, error) {
+			for _, nodePredicate := range predicates {
+				if matches, err := nodePredicate(row); err != nil || matches {
+					return matches, err
+				}
+			}
+
+			return false, nil
+		}, nil
+	case datasource.NotExpression:
+		// Like an empty condition in SQL, negating nothing matches everything
+		if node.Expression() == nil {
+			return matchAll, nil
+		}
+
+		nodePredicate, err := th.compileFilter(node.Expression(), schema, environment)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(row map[string]interface{}) (bool, error) {
+			matches, err := nodePredicate(row)
+			return !matches, err
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown filter expression %T", filterExpression)
+	}
+}
+
+func (th Connector) compileFilters(expressions []datasource.FilterExpression, schema config.ResolvedTableSchema,
+	environment dates.Environment) ([]predicate, error) {
+	predicates := make([]predicate, len(expressions))
+	for i, expression := range expressions {
+		var err error
+		if predicates[i], err = th.compileFilter(expression, schema, environment); err != nil {
+			return nil, err
+		}
+	}
+
+	return predicates, nil
+}
+
+// Compiles a single FilterGroup, of which the filters are OR'ed. An empty FilterGroup matches everything.
+func (th Connector) compileFilterGroup(filterGroup datasource.FilterGroup, schema config.ResolvedTableSchema,
+	environment dates.Environment) (predicate, error) {
+	filters := filterGroup.Filters()
+	if len(filters) == 0 {
+		return matchAll, nil
+	}
+
+	column, err := schema.Column(filterGroup.Path())
+	if err != nil {
+		return nil, fmt.Errorf("unknown filter column %s", filterGroup.Path())
+	}
+
+	columnFilter := th.filters[column.Filter]
+	if columnFilter == nil {
+		return nil, fmt.Errorf("unknown filter %s on column %s", column.Filter, column.Path)
+	}
+
+	matchers := make([]matcher, len(filters))
+	for i, groupFilter := range filters {
+		if matchers[i], err = compileFilterValue(columnFilter, groupFilter, environment); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(row map[string]interface{}) (bool, error) {
+		for _, valueMatcher := range matchers {
+			matches, err := valueMatcher(row[column.Path])
+			if err != nil || matches {
+				return matches, err
+			}
+		}
+
+		return false, nil
+	}, nil
+}
+
+// matcher checks if a single column value matches.
+type matcher func(value interface{}) (bool, error)
+
+// Compiles a single filter into a matcher. IN matches any of its values, and BETWEEN the
+// inclusive range of its bounds. The terms of substring modes are matched literally, so they
+// are not parsed by the filter.
+func compileFilterValue(columnFilter Filter, groupFilter datasource.Filter, environment dates.Environment) (matcher, error) {
+	filterMode := groupFilter.FilterMode()
+	if !supportsMode(columnFilter, filterMode) {
+		return nil, fmt.Errorf("unsupported filter mode %s", filterMode)
+	}
+
+	values, err := filterValues(groupFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filterMode {
+	case tableaux.FilterIsNull, tableaux.FilterIsNotNull:
+		return func(value interface{}) (bool, error) {
+			return (normalizeValue(value) == nil) == (filterMode == tableaux.FilterIsNull), nil
+		}, nil
+	case tableaux.FilterContains, tableaux.FilterStartsWith, tableaux.FilterEndsWith:
+		term, isString := values[0].(string)
+		if !isString {
+			return nil, fmt.Errorf("cannot parse value %v as string", values[0])
+		}
+
+		return func(value interface{}) (bool, error) {
+			return matchSubstring(value, term, filterMode), nil
+		}, nil
+	}
+
+	parsedValues := make([]interface{}, len(values))
+	for i, value := range values {
+		if parsedValues[i], err = parseFilterValue(columnFilter, value, environment); err != nil {
+			return nil, err
+		}
+	}
+
+	switch filterMode {
+	case tableaux.FilterIn:
+		return func(value interface{}) (bool, error) {
+			for _, parsedValue := range parsedValues {
+				if matches, err := columnFilter.Match(value, parsedValue, tableaux.FilterEquals); err != nil || matches {
+					return matches, err
+				}
+			}
+
+			return false, nil
+		}, nil
+	case tableaux.FilterBetween:
+		if _, isRangeFilter := columnFilter.(RangeFilter); isRangeFilter {
+			// Ranges from the start of the lower bound, up to the end of the upper bound
+			bounds := Range{
+				From: parsedValues[0].(Range).From,
+				To:   parsedValues[1].(Range).To,
+			}
+
+			return func(value interface{}) (bool, error) {
+				return columnFilter.Match(value, bounds, tableaux.FilterEquals)
+			}, nil
+		}
+
+		return func(value interface{}) (bool, error) {
+			if matches, err := columnFilter.Match(value, parsedValues[0], tableaux.FilterGreaterEquals); err != nil || !matches {
+				return false, err
+			}
+
+			return columnFilter.Match(value, parsedValues[1], tableaux.FilterLesserEquals)
+		}, nil
+	default:
+		return func(value interface{}) (bool, error) {
+			return columnFilter.Match(value, parsedValues[0], filterMode)
+		}, nil
+	}
+}
+
+// Splits the value of a filter into its values. IN filters hold a non-empty list of values,
+// BETWEEN filters a list of exactly two values, and null checks no value at all.
+func filterValues(groupFilter datasource.Filter) ([]interface{}, error) {
+	filterMode := groupFilter.FilterMode()
+
+	switch filterMode {
+	case tableaux.FilterIsNull, tableaux.FilterIsNotNull:
+		return nil, nil
+	case tableaux.FilterIn:
+		values, isList := groupFilter.Value().([]interface{})
+		if !isList || len(values) == 0 {
+			return nil, fmt.Errorf("filter mode %s requires a non-empty list of values", filterMode)
+		}
+
+		return values, nil
+	case tableaux.FilterBetween:
+		values, isList := groupFilter.Value().([]interface{})
+		if !isList || len(values) != 2 {
+			return nil, fmt.Errorf("filter mode %s requires a list of two values", filterMode)
+		}
+
+		return values, nil
+	default:
+		return []interface{}{groupFilter.Value()}, nil
+	}
+}
+
+// Compiles the global search into a predicate, which matches rows of which any searchable
+// column matches the term.
+func (th Connector) compileSearch(term string, columns []config.TableSchemaColumn, locale string) predicate {
+	if term == "" {
+		return matchAll
+	}
+
+	return func(row map[string]interface{}) (bool, error) {
+		for _, column := range columns {
+			searcher, canSearch := th.filters[column.Filter].(Searcher)
+			if canSearch && searcher.Search(term, row[column.Path], column, locale) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+}
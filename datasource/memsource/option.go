@@ -0,0 +1,16 @@
+package memsource
+
+import (
+	"github.com/tableaux-project/tableaux/datasource/dates"
+)
+
+// Option configures a Connector on creation.
+type Option func(connector *Connector)
+
+// WithClock replaces the clock, which relative dates in filters are resolved against. It
+// defaults to time.Now.
+func WithClock(clock dates.Clock) Option {
+	return func(connector *Connector) {
+		connector.clock = clock
+	}
+}
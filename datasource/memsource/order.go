@@ -0,0 +1,121 @@
+package memsource
+
+import (
+	"sort"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+)
+
+// Sorter is the in-memory counterpart of the sql sorters. It converts a column value into
+// the key, by which rows are ordered.
+type Sorter interface {
+	// SortKey converts the column value into its sort key, in the given locale.
+	SortKey(value interface{}, column config.TableSchemaColumn, locale string) interface{}
+}
+
+// directSorter orders by the column value itself.
+type directSorter struct {
+}
+
+func (sorter directSorter) SortKey(value interface{}, _ config.TableSchemaColumn, _ string) interface{} {
+	return value
+}
+
+// enumSorter orders enum columns by the translation of their enum keys. The suffix is appended
+// to the translation key, to order by e.g. the short translation. Keys which are unknown to
+// the enum are ordered first, and keys without translation right after them.
+type enumSorter struct {
+	mapper     config.EnumMapper
+	translator config.Translator
+	suffix     string
+}
+
+func (sorter enumSorter) SortKey(value interface{}, column config.TableSchemaColumn, locale string) interface{} {
+	key, isKey := normalizeValue(value).(string)
+	if !isKey {
+		return nil
+	}
+
+	translationKey, err := sorter.mapper.TranslationKeyInEnum(column.Type, key)
+	if err != nil {
+		return nil
+	}
+
+	translation, _ := sorter.translator.Translate(locale, translationKey+sorter.suffix)
+	return translation
+}
+
+// Sorts the rows by the given orders. Rows which are equal in all orders retain their
+// order in the table, which is the in-memory equivalent of the primary key order.
+func (th Connector) sortRows(rows []map[string]interface{}, orders []datasource.Order, schema config.ResolvedTableSchema,
+	locale string) {
+	if len(orders) == 0 {
+		return
+	}
+
+	// Calculate the sort keys upfront, as they might involve translations
+	sortKeys := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		sortKeys[i] = make([]interface{}, len(orders))
+
+		for j, value := range orders {
+			sortKeys[i][j] = th.sortKey(row[value.Path()], value, schema, locale)
+		}
+	}
+
+	indices := make([]int, len(rows))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	sort.SliceStable(indices, func(a, b int) bool {
+		for j, value := range orders {
+			comparison := compareValues(sortKeys[indices[a]][j], sortKeys[indices[b]][j])
+			if value.Direction() == tableaux.OrderDesc {
+				comparison = -comparison
+			}
+
+			if comparison != 0 {
+				return comparison < 0
+			}
+		}
+
+		return false
+	})
+
+	sorted := make([]map[string]interface{}, len(rows))
+	for i, index := range indices {
+		sorted[i] = rows[index]
+	}
+
+	copy(rows, sorted)
+}
+
+// Calculates the sort key of a single value. If the order has sort keys, the value is ordered
+// by its position in them, with values not contained being ordered first.
+func (th Connector) sortKey(value interface{}, columnOrder datasource.Order, schema config.ResolvedTableSchema,
+	locale string) interface{} {
+	if predefinedSortKeys := columnOrder.SortKeys(); len(predefinedSortKeys) > 0 {
+		for i, sortKey := range predefinedSortKeys {
+			if compareValues(value, sortKey) == 0 {
+				return int64(i)
+			}
+		}
+
+		return int64(-1)
+	}
+
+	column, err := schema.Column(columnOrder.Path())
+	if err != nil {
+		return value
+	}
+
+	sorter := th.sorters[column.Order]
+	if sorter == nil {
+		return value
+	}
+
+	return sorter.SortKey(value, column, locale)
+}
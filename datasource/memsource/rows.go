@@ -0,0 +1,100 @@
+package memsource
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// The version of the continuation token format.
+const continuationVersion = 1
+
+// ErrInvalidContinuation indicates that a continuation token is malformed.
+var ErrInvalidContinuation = errors.New("invalid continuation token")
+
+// Rows is the in-memory implementation of datasource.Rows, which iterates over a prepared page.
+type Rows struct {
+	rows    []map[string]interface{}
+	columns []string
+	index   int
+
+	current map[string]interface{}
+	closed  bool
+
+	totalCount    uint64
+	filteredCount uint64
+	continuation  string
+}
+
+// Next advances to the next row. The row only contains the selected columns.
+func (r *Rows) Next() bool {
+	if r.closed || r.index+1 >= len(r.rows) {
+		return false
+	}
+
+	r.index++
+
+	row := make(map[string]interface{}, len(r.columns))
+	for _, columnPath := range r.columns {
+		row[columnPath] = r.rows[r.index][columnPath]
+	}
+
+	r.current = row
+	return true
+}
+
+// Row returns the current row.
+func (r *Rows) Row() map[string]interface{} {
+	return r.current
+}
+
+// Err always returns nil, as iterating in-memory data cannot fail.
+func (r *Rows) Err() error {
+	return nil
+}
+
+// Continuation returns the continuation token for the next page, if there is one.
+func (r *Rows) Continuation() (string, error) {
+	return r.continuation, nil
+}
+
+// Counts returns the total and filtered count, which are known upfront.
+func (r *Rows) Counts() (uint64, uint64, error) {
+	return r.totalCount, r.filteredCount, nil
+}
+
+// Close marks the Rows as closed.
+func (r *Rows) Close() error {
+	r.closed = true
+	return nil
+}
+
+type continuationToken struct {
+	Version int    `json:"v"`
+	Offset  uint64 `json:"o"`
+}
+
+// Encodes the offset of the next page into an opaque continuation token.
+func encodeContinuation(offset uint64) string {
+	encoded, _ := json.Marshal(continuationToken{
+		Version: continuationVersion,
+		Offset:  offset,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// Decodes a continuation token back into the offset of the next page.
+func decodeContinuation(encoded string) (uint64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, ErrInvalidContinuation
+	}
+
+	var token continuationToken
+	if err := json.Unmarshal(decoded, &token); err != nil || token.Version != continuationVersion {
+		return 0, ErrInvalidContinuation
+	}
+
+	return token.Offset, nil
+}
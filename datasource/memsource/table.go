@@ -0,0 +1,61 @@
+package memsource
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// The struct tag, which maps a struct field to a column path.
+const structTag = "tableaux"
+
+// Table is the data of a single schema. Each row maps the paths of the schema columns to their
+// values. Values are expected to be already resolved, that is joined values (e.g. the name of
+// an organization of a person) are stored under their full path, and size paths hold the size.
+type Table []map[string]interface{}
+
+// TableFromStructs converts a slice of structs (or pointers to structs) into a Table. Fields are
+// mapped to column paths via the "tableaux" struct tag, e.g. `tableaux:"person_name"`. Fields
+// without the tag are ignored.
+func TableFromStructs(items interface{}) (Table, error) {
+	slice := reflect.ValueOf(items)
+	if slice.Kind() != reflect.Slice {
+		return nil, errors.New("items must be a slice of structs")
+	}
+
+	table := make(Table, 0, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		item := slice.Index(i)
+		for item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				return nil, errors.New("items must not contain nil pointers")
+			}
+
+			item = item.Elem()
+		}
+
+		if item.Kind() != reflect.Struct {
+			return nil, errors.New("items must be a slice of structs")
+		}
+
+		row := make(map[string]interface{})
+		for j := 0; j < item.NumField(); j++ {
+			field := item.Type().Field(j)
+
+			columnPath := field.Tag.Get(structTag)
+			if columnPath == "" || columnPath == "-" {
+				continue
+			}
+
+			if field.PkgPath != "" {
+				return nil, fmt.Errorf("tagged field %s must be exported", field.Name)
+			}
+
+			row[columnPath] = item.Field(j).Interface()
+		}
+
+		table = append(table, row)
+	}
+
+	return table, nil
+}
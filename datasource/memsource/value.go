@@ -0,0 +1,188 @@
+package memsource
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The layouts, in which strings are compared against time values.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Normalizes a value, so it can be compared. Signed integers are converted to int64, unsigned
+// integers to int64 (or uint64, if too large), floats and JSON numbers to float64 and byte
+// slices to strings.
+func normalizeValue(value interface{}) interface{} {
+	switch converted := value.(type) {
+	case nil, bool, string, int64, float64, time.Time:
+		return value
+	case []byte:
+		return string(converted)
+	case json.Number:
+		if float64Value, err := converted.Float64(); err == nil {
+			return float64Value
+		}
+
+		return string(converted)
+	case *time.Time:
+		if converted == nil {
+			return nil
+		}
+
+		return *converted
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflected.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if reflected.Uint() > math.MaxInt64 {
+			return reflected.Uint()
+		}
+
+		return int64(reflected.Uint())
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float()
+	case reflect.Bool:
+		return reflected.Bool()
+	case reflect.String:
+		return reflected.String()
+	case reflect.Ptr:
+		if reflected.IsNil() {
+			return nil
+		}
+
+		return normalizeValue(reflected.Elem().Interface())
+	default:
+		return value
+	}
+}
+
+// Returns the rank of a normalized value in the order of different kinds of values.
+func kindRank(value interface{}) int {
+	switch value.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case int64, uint64, float64:
+		return 2
+	case time.Time:
+		return 3
+	case string:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// Compares two values, and returns -1, 0 or 1 if a is smaller, equal or larger than b. nil is
+// smaller than any other value, numbers are compared regardless of their type, and strings are
+// parsed if compared to time values. Values of unrelated kinds are ordered by their kind.
+func compareValues(a, b interface{}) int {
+	a, b = normalizeValue(a), normalizeValue(b)
+
+	// Strings are compared against time values in their parsed form
+	if aString, isString := a.(string); isString {
+		if _, isTime := b.(time.Time); isTime {
+			if parsed, err := parseTime(aString); err == nil {
+				a = parsed
+			}
+		}
+	} else if bString, isString := b.(string); isString {
+		if _, isTime := a.(time.Time); isTime {
+			if parsed, err := parseTime(bString); err == nil {
+				b = parsed
+			}
+		}
+	}
+
+	if aRank, bRank := kindRank(a), kindRank(b); aRank != bRank {
+		return compareInts(int64(aRank), int64(bRank))
+	}
+
+	switch aValue := a.(type) {
+	case nil:
+		return 0
+	case bool:
+		bValue := b.(bool)
+		if aValue == bValue {
+			return 0
+		} else if !aValue {
+			return -1
+		}
+
+		return 1
+	case int64:
+		if bValue, isInt := b.(int64); isInt {
+			return compareInts(aValue, bValue)
+		}
+	case time.Time:
+		bValue := b.(time.Time)
+		if aValue.Before(bValue) {
+			return -1
+		} else if aValue.After(bValue) {
+			return 1
+		}
+
+		return 0
+	case string:
+		return strings.Compare(aValue, b.(string))
+	}
+
+	if aFloat, isNumber := toFloat64(a); isNumber {
+		bFloat, _ := toFloat64(b)
+		if aFloat < bFloat {
+			return -1
+		} else if aFloat > bFloat {
+			return 1
+		}
+
+		return 0
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareInts(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+
+	return 0
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch converted := value.(type) {
+	case int64:
+		return float64(converted), true
+	case uint64:
+		return float64(converted), true
+	case float64:
+		return converted, true
+	default:
+		return 0, false
+	}
+}
+
+func parseTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, err
+}
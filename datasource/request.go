@@ -0,0 +1,421 @@
+package datasource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/tableaux-project/tableaux"
+)
+
+// RequestVersion is the current version of the Request wire format. It is written
+// with every marshalled Request, and checked when unmarshalling.
+const RequestVersion = 1
+
+// UnsupportedRequestVersionError indicates that a marshalled Request was written
+// in a wire format version which is not understood.
+type UnsupportedRequestVersionError struct {
+	version int
+}
+
+func (e UnsupportedRequestVersionError) Error() string {
+	return fmt.Sprintf("unsupported request version %d", e.version)
+}
+
+// Request is the canonical description of a data request. It references the schema
+// by its key in the config.SchemaMapper, and columns by their paths, so it can be
+// serialized, cached and logged without access to the schema configuration.
+type Request struct {
+	schema       string
+	columns      []string
+	filters      []FilterGroup
+	where        FilterExpression
+	orders       []Order
+	globalSearch string
+	limit        uint64
+	offset       uint64
+	locale       string
+	timeZone     string
+	continuation string
+}
+
+// Schema returns the key of the schema, under which it is known to the config.SchemaMapper.
+func (r Request) Schema() string {
+	return r.schema
+}
+
+// Columns returns the paths of all selected columns.
+func (r Request) Columns() []string {
+	return r.columns
+}
+
+// Filters returns all FilterGroups, which are to be AND'ed.
+func (r Request) Filters() []FilterGroup {
+	return r.filters
+}
+
+// Where returns the additional FilterExpression, which is AND'ed with the FilterGroups.
+// Where might be nil.
+func (r Request) Where() FilterExpression {
+	return r.where
+}
+
+// FilterExpression returns all filters of the Request as a single expression, that is the
+// FilterGroups AND'ed with the Where expression. If the Request has no filters at all, nil
+// is returned.
+func (r Request) FilterExpression() FilterExpression {
+	return combineFilters(r.filters, r.where)
+}
+
+// Orders returns all orders, in order of precedence.
+func (r Request) Orders() []Order {
+	return r.orders
+}
+
+// GlobalSearch returns the search term which is applied to all searchable columns.
+func (r Request) GlobalSearch() string {
+	return r.globalSearch
+}
+
+// Limit returns the maximum amount of rows to be fetched, or 0 for no limit.
+func (r Request) Limit() uint64 {
+	return r.limit
+}
+
+// Offset returns the amount of rows to be skipped.
+func (r Request) Offset() uint64 {
+	return r.offset
+}
+
+// Locale returns the locale which is used for locale dependent operations, such as
+// ordering by translated enums.
+func (r Request) Locale() string {
+	return r.locale
+}
+
+// TimeZone returns the name of the time zone, in which date and time filter values without an
+// explicit offset are interpreted. An empty name stands for UTC.
+func (r Request) TimeZone() string {
+	return r.timeZone
+}
+
+// Continuation returns the opaque continuation token of a previous, otherwise identical
+// request. If set, data is fetched after the last row of the previous request (keyset
+// pagination), and Offset is ignored.
+func (r Request) Continuation() string {
+	return r.continuation
+}
+
+// RequestBuilder assembles a Request step by step.
+type RequestBuilder struct {
+	request Request
+}
+
+// NewRequestBuilder creates a new RequestBuilder for the schema with the given key.
+func NewRequestBuilder(schema string) *RequestBuilder {
+	return &RequestBuilder{
+		request: Request{
+			schema: schema,
+		},
+	}
+}
+
+// Columns appends selected column paths to the Request.
+func (b *RequestBuilder) Columns(paths ...string) *RequestBuilder {
+	b.request.columns = append(b.request.columns, paths...)
+	return b
+}
+
+// Filters appends FilterGroups to the Request.
+func (b *RequestBuilder) Filters(filters ...FilterGroup) *RequestBuilder {
+	b.request.filters = append(b.request.filters, filters...)
+	return b
+}
+
+// Where sets a FilterExpression, which is AND'ed with the FilterGroups of the Request.
+func (b *RequestBuilder) Where(where FilterExpression) *RequestBuilder {
+	b.request.where = where
+	return b
+}
+
+// Orders appends Orders to the Request.
+func (b *RequestBuilder) Orders(orders ...Order) *RequestBuilder {
+	b.request.orders = append(b.request.orders, orders...)
+	return b
+}
+
+// GlobalSearch sets the global search term of the Request.
+func (b *RequestBuilder) GlobalSearch(globalSearch string) *RequestBuilder {
+	b.request.globalSearch = globalSearch
+	return b
+}
+
+// Limit sets the maximum amount of rows to be fetched.
+func (b *RequestBuilder) Limit(limit uint64) *RequestBuilder {
+	b.request.limit = limit
+	return b
+}
+
+// Offset sets the amount of rows to be skipped.
+func (b *RequestBuilder) Offset(offset uint64) *RequestBuilder {
+	b.request.offset = offset
+	return b
+}
+
+// Locale sets the locale of the Request.
+func (b *RequestBuilder) Locale(locale string) *RequestBuilder {
+	b.request.locale = locale
+	return b
+}
+
+// TimeZone sets the name of the time zone of the Request, e.g. "Europe/Berlin".
+func (b *RequestBuilder) TimeZone(timeZone string) *RequestBuilder {
+	b.request.timeZone = timeZone
+	return b
+}
+
+// Continuation sets the continuation token of a previous request, to fetch the next page.
+func (b *RequestBuilder) Continuation(continuation string) *RequestBuilder {
+	b.request.continuation = continuation
+	return b
+}
+
+// Build returns the assembled Request.
+func (b *RequestBuilder) Build() Request {
+	return b.request
+}
+
+// ----------------------------------------------------------------------------
+// Wire format
+// ----------------------------------------------------------------------------
+
+type requestJSON struct {
+	Version      int             `json:"version"`
+	Schema       string          `json:"schema"`
+	Columns      []string        `json:"columns"`
+	Filters      []FilterGroup   `json:"filters,omitempty"`
+	Where        json.RawMessage `json:"where,omitempty"`
+	Orders       []Order         `json:"orders,omitempty"`
+	GlobalSearch string          `json:"globalSearch,omitempty"`
+	Limit        uint64          `json:"limit,omitempty"`
+	Offset       uint64          `json:"offset,omitempty"`
+	Locale       string          `json:"locale"`
+	TimeZone     string          `json:"timeZone,omitempty"`
+	Continuation string          `json:"continuation,omitempty"`
+}
+
+type filterGroupJSON struct {
+	Path    string   `json:"path"`
+	Filters []Filter `json:"filters"`
+}
+
+type filterJSON struct {
+	FilterMode tableaux.FilterMode `json:"mode"`
+	Value      json.RawMessage     `json:"value"`
+}
+
+type orderJSON struct {
+	Path      string            `json:"path"`
+	Direction tableaux.Order    `json:"direction"`
+	SortKeys  []json.RawMessage `json:"sortKeys,omitempty"`
+}
+
+// MarshalJSON encodes the Request in the current wire format version.
+func (r Request) MarshalJSON() ([]byte, error) {
+	var where json.RawMessage
+	if r.where != nil {
+		var err error
+		if where, err = json.Marshal(r.where); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(requestJSON{
+		Version:      RequestVersion,
+		Schema:       r.schema,
+		Columns:      r.columns,
+		Filters:      r.filters,
+		Where:        where,
+		Orders:       r.orders,
+		GlobalSearch: r.globalSearch,
+		Limit:        r.limit,
+		Offset:       r.offset,
+		Locale:       r.locale,
+		TimeZone:     r.timeZone,
+		Continuation: r.continuation,
+	})
+}
+
+// UnmarshalJSON decodes a Request, and returns an UnsupportedRequestVersionError if
+// the wire format version is not understood.
+func (r *Request) UnmarshalJSON(data []byte) error {
+	var decoded requestJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if decoded.Version != RequestVersion {
+		return UnsupportedRequestVersionError{version: decoded.Version}
+	}
+
+	var where FilterExpression
+	if len(decoded.Where) > 0 {
+		var err error
+		if where, err = unmarshalFilterExpression(decoded.Where); err != nil {
+			return err
+		}
+	}
+
+	*r = Request{
+		schema:       decoded.Schema,
+		columns:      decoded.Columns,
+		filters:      decoded.Filters,
+		where:        where,
+		orders:       decoded.Orders,
+		globalSearch: decoded.GlobalSearch,
+		limit:        decoded.Limit,
+		offset:       decoded.Offset,
+		locale:       decoded.Locale,
+		timeZone:     decoded.TimeZone,
+		continuation: decoded.Continuation,
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the FilterGroup, including its unexported fields.
+func (f FilterGroup) MarshalJSON() ([]byte, error) {
+	return json.Marshal(filterGroupJSON{
+		Path:    f.path,
+		Filters: f.filters,
+	})
+}
+
+// UnmarshalJSON decodes a FilterGroup.
+func (f *FilterGroup) UnmarshalJSON(data []byte) error {
+	var decoded filterGroupJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*f = NewFilterGroup(decoded.Path, decoded.Filters)
+	return nil
+}
+
+// MarshalJSON encodes the Filter, including its unexported fields.
+func (f Filter) MarshalJSON() ([]byte, error) {
+	value, err := json.Marshal(f.value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(filterJSON{
+		FilterMode: f.filterMode,
+		Value:      value,
+	})
+}
+
+// UnmarshalJSON decodes a Filter. Numeric values are decoded as int64 or uint64
+// if they are integral, so they survive a round trip without loss of precision.
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	var decoded filterJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	value, err := decodeValue(decoded.Value)
+	if err != nil {
+		return err
+	}
+
+	*f = NewFilter(decoded.FilterMode, value)
+	return nil
+}
+
+// MarshalJSON encodes the Order, including its unexported fields.
+func (o Order) MarshalJSON() ([]byte, error) {
+	var sortKeys []json.RawMessage
+	for _, sortKey := range o.sortKeys {
+		encoded, err := json.Marshal(sortKey)
+		if err != nil {
+			return nil, err
+		}
+
+		sortKeys = append(sortKeys, encoded)
+	}
+
+	return json.Marshal(orderJSON{
+		Path:      o.path,
+		Direction: o.direction,
+		SortKeys:  sortKeys,
+	})
+}
+
+// UnmarshalJSON decodes an Order. Numeric sort keys are decoded the same way as
+// Filter values.
+func (o *Order) UnmarshalJSON(data []byte) error {
+	var decoded orderJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	var sortKeys []interface{}
+	for _, rawSortKey := range decoded.SortKeys {
+		sortKey, err := decodeValue(rawSortKey)
+		if err != nil {
+			return err
+		}
+
+		sortKeys = append(sortKeys, sortKey)
+	}
+
+	*o = NewOrder(decoded.Path, decoded.Direction, sortKeys)
+	return nil
+}
+
+// Decodes a single raw JSON value. In contrast to the default decoding, numbers are
+// converted to int64 (or uint64, if too large) when they are integral, and are kept as
+// json.Number otherwise, so filters of e.g. decimal columns can parse them without any loss
+// of precision. This also applies to the numbers in lists, e.g. of IN filters.
+func decodeValue(data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return convertNumbers(value), nil
+}
+
+// Converts the integral json.Number values of a decoded value, including the ones in lists.
+func convertNumbers(value interface{}) interface{} {
+	if list, isList := value.([]interface{}); isList {
+		for i, item := range list {
+			list[i] = convertNumbers(item)
+		}
+
+		return list
+	}
+
+	number, isNumber := value.(json.Number)
+	if !isNumber {
+		return value
+	}
+
+	if int64Value, err := strconv.ParseInt(number.String(), 10, 64); err == nil {
+		return int64Value
+	}
+
+	if uint64Value, err := strconv.ParseUint(number.String(), 10, 64); err == nil {
+		return uint64Value
+	}
+
+	return number
+}
@@ -0,0 +1,87 @@
+package datasource
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/tableaux-project/tableaux"
+)
+
+func TestRequestJSONRoundTrip(t *testing.T) {
+	request := NewRequestBuilder("masterdata/persons").
+		Columns("person_name", "person_organization_name").
+		Filters(
+			NewSimpleFilterGroup("person_name", tableaux.FilterEquals, []interface{}{"O'Brien", "Smith"}),
+			NewFilterGroup("person_age", []Filter{
+				NewFilter(tableaux.FilterGreater, int64(42)),
+				NewFilter(tableaux.FilterBetween, []interface{}{int64(18), json.Number("2.5")}),
+				NewFilter(tableaux.FilterIsNull, nil),
+			}),
+		).
+		Where(NewOrExpression(
+			NewSimpleFilterGroup("person_status", tableaux.FilterEquals, []interface{}{"OPEN"}),
+			NewNotExpression(NewSimpleFilterGroup("person_assignee", tableaux.FilterEquals, []interface{}{"me"})),
+			NewAndExpression(),
+		)).
+		Orders(NewOrder("person_country", tableaux.OrderDesc, []interface{}{"DE", int64(1)})).
+		GlobalSearch("search").
+		Limit(10).
+		Offset(20).
+		Locale("de").
+		TimeZone("Europe/Berlin").
+		Continuation("token").
+		Build()
+
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Marshalling request failed: %s", err)
+	}
+
+	var decoded Request
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshalling request failed: %s", err)
+	}
+
+	if !reflect.DeepEqual(request, decoded) {
+		t.Errorf("Request round trip was incorrect, got: %+v, want: %+v.", decoded, request)
+	}
+}
+
+func TestRequestNumberPrecision(t *testing.T) {
+	encoded := `{"version":1,"schema":"products","columns":["product_price"],"filters":[{"path":"product_price","filters":[` +
+		`{"mode":"IN","value":[42,18446744073709551615,12345678901234567890.123456789]}]}]}`
+
+	var decoded Request
+	if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+		t.Fatalf("Unmarshalling request failed: %s", err)
+	}
+
+	want := []interface{}{int64(42), uint64(18446744073709551615), json.Number("12345678901234567890.123456789")}
+	if value := decoded.Filters()[0].Filters()[0].Value(); !reflect.DeepEqual(value, want) {
+		t.Errorf("Decoded value was incorrect, got: %#v, want: %#v.", value, want)
+	}
+}
+
+func TestRequestUnsupportedVersion(t *testing.T) {
+	tables := []string{
+		`{"schema":"persons","columns":["person_name"]}`,
+		`{"version":2,"schema":"persons","columns":["person_name"]}`,
+	}
+
+	for _, table := range tables {
+		var decoded Request
+		err := json.Unmarshal([]byte(table), &decoded)
+
+		if _, isVersionError := err.(UnsupportedRequestVersionError); !isVersionError {
+			t.Errorf("Unmarshalling %s was incorrect, got: %v, want: UnsupportedRequestVersionError.", table, err)
+		}
+
+		var versionErr UnsupportedRequestVersionError
+		if !errors.As(fmt.Errorf("cannot decode request: %w", err), &versionErr) {
+			t.Errorf("Wrapped error of %s was incorrect, got: %v, want: UnsupportedRequestVersionError.", table, err)
+		}
+	}
+}
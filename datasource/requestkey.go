@@ -0,0 +1,124 @@
+package datasource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key returns a normalized key of the request, e.g. for caching. Requests which only differ in
+// the order of their columns or filters share the same key, as they yield the same result.
+func (r Request) Key() string {
+	return hashKey("data", normalizeRequest(r))
+}
+
+// Key returns a normalized key of the facet request, e.g. for caching.
+func (r FacetRequest) Key() string {
+	return hashKey("facets",
+		normalizeRequest(r.request),
+		r.path,
+		string(r.orderBy),
+		string(r.direction),
+		fmt.Sprint(r.limit),
+	)
+}
+
+// Key returns a normalized key of the aggregation request, e.g. for caching. Groups and
+// aggregations keep their order, as it is part of the result.
+func (r AggregationRequest) Key() string {
+	aggregations := make([]string, len(r.aggregations))
+	for i, aggregation := range r.aggregations {
+		aggregations[i] = string(aggregation.Function()) + "(" + aggregation.Path() + ")"
+	}
+
+	return hashKey("aggregation",
+		r.schema,
+		strings.Join(r.groupBy, ","),
+		strings.Join(aggregations, ","),
+		normalizeExpression(r.FilterExpression()),
+		normalizeOrders(r.orders),
+		fmt.Sprint(r.limit),
+		r.locale,
+		r.timeZone,
+	)
+}
+
+// Normalizes a request into a string, which is equal for requests with the same result.
+func normalizeRequest(request Request) string {
+	columns := append([]string{}, request.Columns()...)
+	sort.Strings(columns)
+
+	return strings.Join([]string{
+		request.Schema(),
+		strings.Join(columns, ","),
+		normalizeExpression(request.FilterExpression()),
+		normalizeOrders(request.Orders()),
+		fmt.Sprintf("%q", request.GlobalSearch()),
+		fmt.Sprint(request.Limit()),
+		fmt.Sprint(request.Offset()),
+		request.Locale(),
+		request.TimeZone(),
+		request.Continuation(),
+	}, "|")
+}
+
+// Normalizes a filter expression into a string. The operands of AND and OR expressions, and the
+// filters of a FilterGroup, are sorted, as their order does not change the result.
+func normalizeExpression(expression FilterExpression) string {
+	switch converted := expression.(type) {
+	case nil:
+		return ""
+	case FilterGroup:
+		filters := make([]string, len(converted.Filters()))
+		for i, filter := range converted.Filters() {
+			filters[i] = fmt.Sprintf("%s %T:%#v", filter.FilterMode(), filter.Value(), filter.Value())
+		}
+
+		sort.Strings(filters)
+		return fmt.Sprintf("%q[%s]", converted.Path(), strings.Join(filters, ";"))
+	case AndExpression:
+		return "and(" + normalizeExpressions(converted.Expressions()) + ")"
+	case OrExpression:
+		return "or(" + normalizeExpressions(converted.Expressions()) + ")"
+	case NotExpression:
+		return "not(" + normalizeExpression(converted.Expression()) + ")"
+	default:
+		// Unknown expressions cannot be normalized, but must still result in distinct keys
+		return fmt.Sprintf("%T:%#v", expression, expression)
+	}
+}
+
+func normalizeExpressions(expressions []FilterExpression) string {
+	normalized := make([]string, len(expressions))
+	for i, expression := range expressions {
+		normalized[i] = normalizeExpression(expression)
+	}
+
+	sort.Strings(normalized)
+	return strings.Join(normalized, ",")
+}
+
+// Normalizes orders into a string. The orders keep their order, as it changes the result.
+func normalizeOrders(orders []Order) string {
+	normalized := make([]string, len(orders))
+	for i, order := range orders {
+		normalized[i] = fmt.Sprintf("%q %s %#v", order.Path(), order.Direction(), order.SortKeys())
+	}
+
+	return strings.Join(normalized, ",")
+}
+
+// Hashes the parts of a key, so keys have a fixed size regardless of the request.
+func hashKey(kind string, parts ...string) string {
+	hash := sha256.New()
+	hash.Write([]byte(kind))
+
+	for _, part := range parts {
+		// Prefix each part with its length, so parts cannot run into each other
+		fmt.Fprintf(hash, "|%d:%s", len(part), part)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,90 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/tableaux-project/tableaux"
+)
+
+func TestRequestKey(t *testing.T) {
+	tables := []struct {
+		a, b  Request
+		equal bool
+	}{
+		{
+			NewRequestBuilder("persons").Columns("person_id", "person_name").
+				Filters(NewSimpleFilterGroup("person_id", tableaux.FilterEquals, []interface{}{int64(1)})).
+				Where(NewSimpleFilterGroup("person_name", tableaux.FilterEquals, []interface{}{"Alice"})).Build(),
+			NewRequestBuilder("persons").Columns("person_name", "person_id").
+				Filters(NewSimpleFilterGroup("person_name", tableaux.FilterEquals, []interface{}{"Alice"})).
+				Where(NewSimpleFilterGroup("person_id", tableaux.FilterEquals, []interface{}{int64(1)})).Build(),
+			true,
+		},
+		{
+			NewRequestBuilder("persons").Columns("person_id").Orders(NewOrder("person_id", tableaux.OrderAsc, nil), NewOrder("person_name", tableaux.OrderAsc, nil)).Build(),
+			NewRequestBuilder("persons").Columns("person_id").Orders(NewOrder("person_name", tableaux.OrderAsc, nil), NewOrder("person_id", tableaux.OrderAsc, nil)).Build(),
+			false,
+		},
+		{
+			NewRequestBuilder("persons").Columns("person_id").Limit(10).Build(),
+			NewRequestBuilder("persons").Columns("person_id").Limit(10).Offset(10).Build(),
+			false,
+		},
+		{
+			NewRequestBuilder("persons").Columns("person_id").Locale("en").Build(),
+			NewRequestBuilder("persons").Columns("person_id").Locale("de").Build(),
+			false,
+		},
+		{
+			NewRequestBuilder("persons").Columns("person_id").TimeZone("Europe/Berlin").Build(),
+			NewRequestBuilder("persons").Columns("person_id").TimeZone("America/New_York").Build(),
+			false,
+		},
+	}
+
+	for _, table := range tables {
+		if equal := table.a.Key() == table.b.Key(); equal != table.equal {
+			t.Errorf("Keys of %+v and %+v were incorrect, got equal: %t, want: %t.", table.a, table.b, equal, table.equal)
+		}
+	}
+}
+
+func TestNormalizeExpression(t *testing.T) {
+	tables := []struct {
+		a, b  FilterExpression
+		equal bool
+	}{
+		{
+			NewOrExpression(
+				NewSimpleFilterGroup("a", tableaux.FilterEquals, []interface{}{"1", "2"}),
+				NewNotExpression(NewSimpleFilterGroup("b", tableaux.FilterEquals, []interface{}{true})),
+			),
+			NewOrExpression(
+				NewNotExpression(NewSimpleFilterGroup("b", tableaux.FilterEquals, []interface{}{true})),
+				NewSimpleFilterGroup("a", tableaux.FilterEquals, []interface{}{"2", "1"}),
+			),
+			true,
+		},
+		{
+			NewSimpleFilterGroup("a", tableaux.FilterEquals, []interface{}{"1"}),
+			NewSimpleFilterGroup("a", tableaux.FilterEquals, []interface{}{int64(1)}),
+			false,
+		},
+		{
+			NewAndExpression(NewSimpleFilterGroup("a", tableaux.FilterEquals, []interface{}{"1"})),
+			NewOrExpression(NewSimpleFilterGroup("a", tableaux.FilterEquals, []interface{}{"1"})),
+			false,
+		},
+		{
+			NewSimpleFilterGroup("a", tableaux.FilterEquals, []interface{}{"1"}),
+			NewSimpleFilterGroup("a", tableaux.FilterNotEquals, []interface{}{"1"}),
+			false,
+		},
+	}
+
+	for _, table := range tables {
+		if equal := normalizeExpression(table.a) == normalizeExpression(table.b); equal != table.equal {
+			t.Errorf("Normalizing %v and %v was incorrect, got equal: %t, want: %t.", table.a, table.b, equal, table.equal)
+		}
+	}
+}
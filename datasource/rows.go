@@ -0,0 +1,41 @@
+package datasource
+
+// Rows is a cursor over the result of a data request, which yields one type-safe row
+// at a time instead of materializing the whole Result. Its usage mirrors sql.Rows:
+//
+//	rows, err := connector.FetchRows(ctx, request)
+//	if err != nil {
+//		...
+//	}
+//	defer rows.Close()
+//
+//	for rows.Next() {
+//		row := rows.Row()
+//		...
+//	}
+//	if err := rows.Err(); err != nil {
+//		...
+//	}
+type Rows interface {
+	// Next advances to the next row, and returns false if there are no more rows,
+	// or an error occurred. Err must be consulted to distinguish the two cases.
+	Next() bool
+
+	// Row returns the current row, mapping the fetched paths to their type-safe values.
+	Row() map[string]interface{}
+
+	// Err returns the error which occurred during iteration, if any.
+	Err() error
+
+	// Continuation returns the continuation token for the next page, once all rows have been
+	// iterated. The token is empty, if there is no next page, or the request is not limited.
+	Continuation() (string, error)
+
+	// Counts blocks until the total and filtered count of the request are available.
+	// Counts must be called before Close, as closing aborts any pending counting.
+	Counts() (totalCount uint64, filteredCount uint64, err error)
+
+	// Close releases all resources held by the Rows. Close is idempotent, and does
+	// not affect the result of Err.
+	Close() error
+}
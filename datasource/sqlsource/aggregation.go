@@ -0,0 +1,501 @@
+package sqlsource
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/birkirb/loggers.v1/log"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/util"
+)
+
+// ValidateAggregation validates if the aggregation request can be served. In addition to the
+// checks of ValidateRequest, each aggregate function must fit the type of its column. All
+// problems found are returned as datasource.ValidationErrors.
+func (th Connector) ValidateAggregation(ctx context.Context, request datasource.AggregationRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var errs datasource.ValidationErrors
+
+	if len(request.GroupBy()) == 0 && len(request.Aggregations()) == 0 {
+		errs.Add(datasource.ValidationInvalidRequest, datasource.CodeNoAggregations, "", "no groups or aggregations selected")
+	}
+
+	schema, err := th.schemaMapper.ResolvedSchema(request.Schema())
+	if err != nil {
+		errs.Add(datasource.ValidationUnknownSchema, datasource.CodeUnknownSchema, "", "unknown schema %s", request.Schema())
+		return errs
+	}
+
+	if _, err := th.translator.Language(request.Locale()); err != nil {
+		errs.Add(datasource.ValidationUnknownLocale, datasource.CodeUnknownLocale, "", "unknown locale %s", request.Locale())
+	}
+
+	environment, err := th.environment(request.TimeZone(), request.Locale())
+	if err != nil {
+		errs.Add(datasource.ValidationUnknownTimeZone, datasource.CodeUnknownTimeZone, "", "unknown time zone %s", request.TimeZone())
+		environment = dates.NewEnvironment(time.UTC, request.Locale(), th.clock)
+	}
+
+	orderPaths := make(map[string]struct{})
+
+	for _, columnPath := range request.GroupBy() {
+		orderPaths[columnPath] = struct{}{}
+
+		column, err := schema.Column(columnPath)
+		if err != nil {
+			errs.Add(datasource.ValidationUnknownColumn, datasource.CodeUnknownGroupColumn, columnPath,
+				"unknown group column %s", columnPath)
+			continue
+		}
+
+		if th.resolvers[column.PathResolver] == nil {
+			errs.Add(datasource.ValidationUnknownResolver, datasource.CodeUnknownPathResolver, columnPath,
+				"unknown path resolver %s on column %s", column.PathResolver, columnPath)
+		}
+
+		if th.sorters[column.Order] == nil {
+			errs.Add(datasource.ValidationUnknownOrder, datasource.CodeUnknownOrder, columnPath,
+				"unknown order %s on column %s", column.Order, columnPath)
+		}
+	}
+
+	for _, aggregation := range request.Aggregations() {
+		if aggregation.Path() == "" {
+			if aggregation.Function() != tableaux.AggregateCount {
+				errs.Add(datasource.ValidationInvalidRequest, datasource.CodeAggregateRequiresColumn, "",
+					"aggregate %s requires a column", aggregation.Function())
+			}
+		} else if column, err := schema.Column(aggregation.Path()); err != nil {
+			errs.Add(datasource.ValidationUnknownColumn, datasource.CodeUnknownAggregateColumn, aggregation.Path(),
+				"unknown aggregate column %s", aggregation.Path())
+		} else {
+			if th.resolvers[column.PathResolver] == nil {
+				errs.Add(datasource.ValidationUnknownResolver, datasource.CodeUnknownPathResolver, aggregation.Path(),
+					"unknown path resolver %s on column %s", column.PathResolver, aggregation.Path())
+			}
+
+			if !datasource.AggregationSupportsType(aggregation.Function(), column.Type) {
+				errs.Add(datasource.ValidationUnsupportedAggregate, datasource.CodeUnsupportedAggregate, aggregation.Path(),
+					"aggregate %s is not applicable to column %s of type %s", aggregation.Function(), aggregation.Path(), column.Type)
+			}
+		}
+
+		if _, exists := orderPaths[aggregation.Alias()]; exists {
+			errs.Add(datasource.ValidationInvalidRequest, datasource.CodeDuplicateAggregate, aggregation.Alias(),
+				"duplicate aggregate %s", aggregation.Alias())
+		}
+
+		orderPaths[aggregation.Alias()] = struct{}{}
+	}
+
+	th.validateFilters(&errs, request.FilterExpression(), schema, environment)
+
+	for _, columnOrder := range request.Orders() {
+		if _, exists := orderPaths[columnOrder.Path()]; !exists {
+			errs.Add(datasource.ValidationUnknownColumn, datasource.CodeUnknownOrderColumn, columnOrder.Path(),
+				"unknown order column %s", columnOrder.Path())
+		}
+	}
+
+	return errs.Err()
+}
+
+// FetchAggregation groups all rows matching the request, and aggregates each group. The aggregate
+// functions and orders end up in the query, so they are checked even if the request was not
+// validated before.
+func (th Connector) FetchAggregation(ctx context.Context, request datasource.AggregationRequest) (_ *datasource.AggregationResult, err error) {
+	start := time.Now()
+
+	schema, err := th.schemaMapper.ResolvedSchema(request.Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	orderPaths := make(map[string]struct{})
+
+	groupColumns := make([]config.TableSchemaColumn, len(request.GroupBy()))
+	for i, columnPath := range request.GroupBy() {
+		groupColumns[i], err = schema.Column(columnPath)
+		if err != nil {
+			return nil, err
+		}
+
+		orderPaths[columnPath] = struct{}{}
+	}
+
+	var aggregateColumns []config.TableSchemaColumn
+	for _, aggregation := range request.Aggregations() {
+		orderPaths[aggregation.Alias()] = struct{}{}
+
+		// Counting rows does not involve any column
+		if aggregation.Path() == "" {
+			if aggregation.Function() != tableaux.AggregateCount {
+				return nil, fmt.Errorf("aggregate %s requires a column", aggregation.Function())
+			}
+
+			continue
+		}
+
+		column, err := schema.Column(aggregation.Path())
+		if err != nil {
+			return nil, err
+		}
+
+		if !datasource.AggregationSupportsType(aggregation.Function(), column.Type) {
+			return nil, fmt.Errorf("aggregate %s is not applicable to column %s of type %s", aggregation.Function(), aggregation.Path(), column.Type)
+		}
+
+		aggregateColumns = append(aggregateColumns, column)
+	}
+
+	for _, columnOrder := range request.Orders() {
+		if _, exists := orderPaths[columnOrder.Path()]; !exists {
+			return nil, fmt.Errorf("unknown order column %s", columnOrder.Path())
+		}
+	}
+
+	queryString, arguments, err := th.aggregationQuery(request, schema, groupColumns, aggregateColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregationResult := datasource.AggregationResult{}
+
+	observation := th.observeQuery(ctx, QueryAggregation, schema.OriginalSchema().Entity, queryString, arguments.Values())
+	defer func() {
+		observation.finish(uint64(len(aggregationResult)), err)
+	}()
+
+	statement, err := th.dbConnector.DatabaseObject().PrepareContext(ctx, queryString)
+	if err != nil {
+		log.WithField("query", queryString).Error("Failed to prepare query")
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	log.WithFields(
+		"query", queryString,
+		"arguments", arguments.Values(),
+	).Debug("Executing query")
+
+	rows, err := statement.QueryContext(ctx, arguments.Values()...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.LoggingRowsCloser(rows, "aggregation")
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([][]byte, len(types))
+	dest := make([]interface{}, len(types))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+
+	// The type of SUM and AVG depends on the type of the aggregated column
+	columnTypes := make(map[string]string)
+	for _, column := range aggregateColumns {
+		columnTypes[column.Path] = strings.ToLower(column.Type)
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := datasource.AggregationRow{
+			Groups: make(map[string]interface{}, len(groupColumns)),
+			Values: make(map[string]interface{}, len(request.Aggregations())),
+		}
+
+		for i, item := range raw {
+			value, err := th.dbConnector.MakeItemTypeSafe(item, types[i])
+			if err != nil {
+				return nil, err
+			}
+
+			if i < len(groupColumns) {
+				row.Groups[groupColumns[i].Path] = value
+				continue
+			}
+
+			aggregation := request.Aggregations()[i-len(groupColumns)]
+			columnType := columnTypes[aggregation.Path()]
+
+			// Sums and averages of decimals are taken from the raw item, as the database
+			// connector might have converted them to float64 already
+			if isDecimalAggregate(aggregation.Function(), columnType) && item != nil {
+				value = item
+			}
+
+			row.Values[aggregation.Alias()], err = typedAggregateValue(aggregation.Function(), columnType, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		aggregationResult = append(aggregationResult, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	log.WithFields(
+		"time", time.Since(start),
+		"count", len(aggregationResult),
+	).Info("Aggregation fetched")
+
+	return &aggregationResult, nil
+}
+
+// Constructs the aggregation query. The groups are selected first, followed by the aggregates
+// in order of the request.
+func (th Connector) aggregationQuery(request datasource.AggregationRequest, schema config.ResolvedTableSchema,
+	groupColumns, aggregateColumns []config.TableSchemaColumn) (string, *Arguments, error) {
+	queryBuilder := th.dbConnector.QueryBuilder()
+	entity := schema.OriginalSchema().Entity
+	filterExpression := request.FilterExpression()
+
+	// Orders on aliases of aggregates do not take part in joins
+	groupPaths := make(map[string]config.TableSchemaColumn, len(groupColumns))
+	for _, column := range groupColumns {
+		groupPaths[column.Path] = column
+	}
+
+	var groupOrders []datasource.Order
+	for _, columnOrder := range request.Orders() {
+		if _, isGroup := groupPaths[columnOrder.Path()]; isGroup {
+			groupOrders = append(groupOrders, columnOrder)
+		}
+	}
+
+	joinString, err := th.resolveJoinString(mergeColumns(groupColumns, aggregateColumns), groupOrders, schema, filterExpression)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// ---------------------------
+
+	selectColumns := make([]string, 0, len(groupColumns)+len(request.Aggregations()))
+	groupStrings := make([]string, len(groupColumns))
+
+	for i, column := range groupColumns {
+		groupStrings[i] = th.resolvers[column.PathResolver].ResolvePathName(column)
+		selectColumns = append(selectColumns, groupStrings[i]+" AS "+column.Path)
+	}
+
+	for _, aggregation := range request.Aggregations() {
+		aggregated := "*"
+		if aggregation.Path() != "" {
+			column, err := schema.Column(aggregation.Path())
+			if err != nil {
+				return "", nil, err
+			}
+
+			aggregated = th.resolvers[column.PathResolver].ResolvePathName(column)
+		}
+
+		selectColumns = append(selectColumns, string(aggregation.Function())+"("+aggregated+") AS "+aggregation.Alias())
+	}
+
+	// ---------------------------
+
+	// The query parts are assembled in order of appearance, so the bound arguments line up
+	arguments := NewArguments(queryBuilder)
+
+	queryString := strings.Join(selectColumns, ",") + " FROM " + entity
+	if joinString != "" {
+		queryString += " " + joinString
+	}
+
+	environment, err := th.environment(request.TimeZone(), request.Locale())
+	if err != nil {
+		return "", nil, err
+	}
+
+	whereString, err := th.whereString(filterExpression, globalSearch{}, environment, schema, arguments)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if whereString != "" {
+		queryString += " WHERE " + whereString
+	}
+
+	if len(groupStrings) > 0 {
+		queryString += " GROUP BY " + strings.Join(groupStrings, ",")
+	}
+
+	if len(request.Orders()) > 0 {
+		sortColumns := make([]string, len(request.Orders()))
+		for i, value := range request.Orders() {
+			column, isGroup := groupPaths[value.Path()]
+			if !isGroup {
+				sortColumns[i] = queryBuilder.OrderColumn(value.Path(), value.Direction())
+				continue
+			}
+
+			resolvedPath := th.resolvers[column.PathResolver].ResolvePathName(column)
+			sortColumns[i], err = OrderColumn(queryBuilder, resolvedPath, column, th.sorters[column.Order], value, request.Locale(), arguments)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
+		queryString += " ORDER BY " + strings.Join(sortColumns, ",")
+	}
+
+	if request.Limit() > 0 {
+		queryString = queryBuilder.SelectWithLimitQuery(queryString, arguments.Bind(request.Limit()), "")
+	} else {
+		queryString = "SELECT " + queryString
+	}
+
+	return queryString, arguments, nil
+}
+
+// Converts an aggregated value to the type documented on datasource.AggregationRow, as
+// databases differ in the types they return for aggregates (e.g. decimals for SUM). The type of
+// SUM and AVG depends on the type of the aggregated column.
+func typedAggregateValue(function tableaux.Aggregate, columnType string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch function {
+	case tableaux.AggregateCount:
+		count, err := aggregateToInt64(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return uint64(count), nil
+	case tableaux.AggregateAvg:
+		if isDecimalAggregate(function, columnType) {
+			return aggregateToDecimal(value)
+		}
+
+		return aggregateToFloat64(value)
+	case tableaux.AggregateSum:
+		if datasource.IsIntegralColumnType(columnType) {
+			return aggregateToInt64(value)
+		}
+
+		if isDecimalAggregate(function, columnType) {
+			return aggregateToDecimal(value)
+		}
+
+		return aggregateToFloat64(value)
+	default:
+		return value, nil
+	}
+}
+
+func aggregateToInt64(value interface{}) (int64, error) {
+	switch converted := value.(type) {
+	case int64:
+		return converted, nil
+	case uint64:
+		return int64(converted), nil
+	case float64:
+		return int64(converted), nil
+	case []byte:
+		return aggregateStringToInt64(string(converted))
+	case string:
+		return aggregateStringToInt64(converted)
+	default:
+		return 0, fmt.Errorf("cannot convert aggregate of type %T to integer", value)
+	}
+}
+
+// Parses an integral aggregate, which might be returned as a decimal (e.g. "42.000").
+func aggregateStringToInt64(value string) (int64, error) {
+	if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return integer, nil
+	}
+
+	decimal, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(decimal), nil
+}
+
+func aggregateToFloat64(value interface{}) (float64, error) {
+	switch converted := value.(type) {
+	case float64:
+		return converted, nil
+	case int64:
+		return float64(converted), nil
+	case uint64:
+		return float64(converted), nil
+	case []byte:
+		return strconv.ParseFloat(string(converted), 64)
+	case string:
+		return strconv.ParseFloat(converted, 64)
+	default:
+		return 0, fmt.Errorf("cannot convert aggregate of type %T to float", value)
+	}
+}
+
+// Converts a decimal aggregate into the literal of the decimal number, so it is returned
+// without any loss of precision.
+func aggregateToDecimal(value interface{}) (string, error) {
+	switch converted := value.(type) {
+	case []byte:
+		return aggregateStringToDecimal(string(converted))
+	case string:
+		return aggregateStringToDecimal(converted)
+	case float64:
+		return strconv.FormatFloat(converted, 'f', -1, 64), nil
+	case int64:
+		return strconv.FormatInt(converted, 10), nil
+	case uint64:
+		return strconv.FormatUint(converted, 10), nil
+	default:
+		return "", fmt.Errorf("cannot convert aggregate of type %T to decimal", value)
+	}
+}
+
+// Checks whether the aggregate of a column is returned as decimal, which is the case for sums
+// and averages of decimal columns.
+func isDecimalAggregate(function tableaux.Aggregate, columnType string) bool {
+	return columnType == "decimal" && (function == tableaux.AggregateSum || function == tableaux.AggregateAvg)
+}
+
+// Checks that the string is a decimal number. Numbers with an exponent stem from floats (e.g. of
+// SQLite, which has no decimals), and are expanded, as the literal of a decimal has none.
+func aggregateStringToDecimal(value string) (string, error) {
+	if strings.ContainsAny(value, "eE") {
+		float, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", err
+		}
+
+		return strconv.FormatFloat(float, 'f', -1, 64), nil
+	}
+
+	if _, ok := new(big.Rat).SetString(value); !ok || strings.Contains(value, "/") {
+		return "", fmt.Errorf("cannot convert aggregate %s to decimal", value)
+	}
+
+	return value, nil
+}
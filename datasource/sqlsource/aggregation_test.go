@@ -0,0 +1,86 @@
+package sqlsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/filter"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/order"
+)
+
+func TestTypedAggregateValue(t *testing.T) {
+	tables := []struct {
+		function   tableaux.Aggregate
+		columnType string
+		value      interface{}
+		typed      interface{}
+	}{
+		{tableaux.AggregateCount, "", int64(42), uint64(42)},
+		{tableaux.AggregateCount, "", []byte("42"), uint64(42)},
+		{tableaux.AggregateSum, "long", "1337.000", int64(1337)},
+		{tableaux.AggregateSum, "integer", int64(1337), int64(1337)},
+		{tableaux.AggregateSum, "float", "13.5", 13.5},
+		{tableaux.AggregateAvg, "integer", int64(2), float64(2)},
+		{tableaux.AggregateAvg, "float", []byte("2.5"), 2.5},
+		// Neither value can be represented by a float64 exactly
+		{tableaux.AggregateSum, "decimal", []byte("12345678901234567.89"), "12345678901234567.89"},
+		{tableaux.AggregateAvg, "decimal", "0.10000000000000000001", "0.10000000000000000001"},
+		{tableaux.AggregateSum, "decimal", []byte("1e+21"), "1000000000000000000000"},
+		{tableaux.AggregateSum, "decimal", int64(42), "42"},
+		{tableaux.AggregateMin, "date", "2018-01-01", "2018-01-01"},
+		{tableaux.AggregateMax, "long", nil, nil},
+	}
+
+	for _, table := range tables {
+		typed, err := typedAggregateValue(table.function, table.columnType, table.value)
+		if err != nil {
+			t.Errorf("typedAggregateValue(%s, %v) failed: %s", table.function, table.value, err)
+			continue
+		}
+
+		if typed != table.typed {
+			t.Errorf("typedAggregateValue(%s, %v) was incorrect, got: %#v, want: %#v.", table.function, table.value, typed, table.typed)
+		}
+	}
+}
+
+func TestFetchAggregationUnvalidated(t *testing.T) {
+	connector, err := newOptionsTestConnector(t,
+		WithFilter("SoundexFilter", filter.PlainString{Common: &filter.Common{}}),
+		WithSorter("CollatedOrder", order.Direct{}),
+		WithPathResolver("LowerPathResolver", lowerResolver{}),
+	)
+	if err != nil {
+		t.Fatalf("NewConnector failed: %s", err)
+	}
+
+	tables := []struct {
+		aggregation datasource.Aggregation
+		order       string
+		err         string
+	}{
+		{datasource.NewAggregation(tableaux.AggregateCount, ""), "person_id; DROP TABLE person",
+			"unknown order column person_id; DROP TABLE person"},
+		{datasource.NewAggregation(tableaux.AggregateCount, ""), "person_name", "unknown order column person_name"},
+		{datasource.NewAggregation(tableaux.Aggregate("SLEEP(10) +"), "person_id"), "person_id",
+			"aggregate SLEEP(10) + is not applicable to column person_id of type long"},
+		{datasource.NewAggregation(tableaux.Aggregate("SLEEP(10) +"), ""), "person_id", "aggregate SLEEP(10) + requires a column"},
+	}
+
+	for _, table := range tables {
+		request := datasource.NewAggregationRequestBuilder("persons").
+			GroupBy("person_id").
+			Aggregations(table.aggregation).
+			Orders(datasource.NewOrder(table.order, tableaux.OrderAsc, nil)).
+			Locale("en").
+			Build()
+
+		// The connector has no database, so the request must be rejected before any query is run
+		_, err := connector.FetchAggregation(context.Background(), request)
+		if err == nil || err.Error() != table.err {
+			t.Errorf("FetchAggregation(%s, %s) was incorrect, got: %v, want: %s.", table.aggregation.Function(), table.order, err, table.err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sqlsource
+
+import (
+	"context"
+
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/util"
+)
+
+// ExplainedQuery is a single query, which a request would execute.
+type ExplainedQuery struct {
+	// The purpose of the query.
+	Kind QueryKind
+
+	// The generated SQL, and its bound arguments.
+	Query     string
+	Arguments []interface{}
+
+	// The rows returned by the EXPLAIN of the database, if requested. Their columns depend on
+	// the database.
+	Plan []map[string]interface{}
+}
+
+// Explanation describes the queries, which a request would execute.
+type Explanation struct {
+	// Whether the primary keys are fetched first, and the data is fetched for these keys.
+	DeferredLoading bool
+
+	Queries []ExplainedQuery
+}
+
+// Explain returns the queries, which fetching the request would execute, without executing
+// them. With deferred loading, the data query depends on the fetched primary keys, and is
+// therefore not part of the explanation - the primary key query is returned instead. If plan
+// is set, the queries are explained by the database, and the resulting plans are returned
+// along with them.
+func (th Connector) Explain(ctx context.Context, request datasource.Request, plan bool) (*Explanation, error) {
+	requestPlan, err := th.planRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{
+		DeferredLoading: requestPlan.deferredLoading,
+	}
+
+	if err := th.explainCountQuery(explanation, QueryTotalCount, requestPlan.schema, nil, globalSearch{}, requestPlan.environment); err != nil {
+		return nil, err
+	}
+
+	if requestPlan.filterExpression != nil || requestPlan.search.term != "" {
+		err := th.explainCountQuery(explanation, QueryFilteredCount, requestPlan.schema, requestPlan.filterExpression, requestPlan.search, requestPlan.environment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kind := QueryData
+	columns := requestPlan.columns
+	if requestPlan.deferredLoading {
+		kind = QueryPrimaryKeys
+		columns = []config.TableSchemaColumn{
+			{Path: requestPlan.primaryKeyPath},
+		}
+	}
+
+	queryString, arguments, err := th.dataQuery(columns, requestPlan.filterExpression, requestPlan.search, requestPlan.environment, requestPlan.orders,
+		requestPlan.keys, requestPlan.schema, requestPlan.limit, requestPlan.offset, requestPlan.locale)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation.Queries = append(explanation.Queries, ExplainedQuery{
+		Kind:      kind,
+		Query:     queryString,
+		Arguments: arguments.Values(),
+	})
+
+	if !plan {
+		return explanation, nil
+	}
+
+	for i, query := range explanation.Queries {
+		explanation.Queries[i].Plan, err = th.explainQuery(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return explanation, nil
+}
+
+func (th Connector) explainCountQuery(explanation *Explanation, kind QueryKind, schema config.ResolvedTableSchema,
+	filterExpression datasource.FilterExpression, search globalSearch, environment dates.Environment) error {
+	queryString, arguments, err := th.countQueryString(schema, filterExpression, search, environment)
+	if err != nil {
+		return err
+	}
+
+	explanation.Queries = append(explanation.Queries, ExplainedQuery{
+		Kind:      kind,
+		Query:     queryString,
+		Arguments: arguments.Values(),
+	})
+
+	return nil
+}
+
+// Runs the EXPLAIN of the database for the query, and returns the resulting rows.
+func (th Connector) explainQuery(ctx context.Context, query ExplainedQuery) ([]map[string]interface{}, error) {
+	rows, err := th.dbConnector.DatabaseObject().QueryContext(ctx, th.dbConnector.QueryBuilder().ExplainQuery(query.Query), query.Arguments...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.LoggingRowsCloser(rows, "explain")
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([][]byte, len(types))
+	dest := make([]interface{}, len(types))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+
+	plan := []map[string]interface{}{}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(types))
+		for i, item := range raw {
+			row[types[i].Name()], err = th.dbConnector.MakeItemTypeSafe(item, types[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		plan = append(plan, row)
+	}
+
+	return plan, rows.Err()
+}
@@ -0,0 +1,201 @@
+package sqlsource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/birkirb/loggers.v1/log"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/util"
+)
+
+// The alias of the row count of each facet value.
+const facetCountAlias = "facet_count"
+
+// FetchFacets returns the distinct values of a single path, and how many rows have each value.
+// Values of enum columns are returned with their translation key.
+func (th Connector) FetchFacets(ctx context.Context, request datasource.FacetRequest) (_ *datasource.FacetResult, err error) {
+	start := time.Now()
+
+	schema, columns, err := th.resolveRequest(request.Request())
+	if err != nil {
+		return nil, err
+	}
+
+	column, err := schema.Column(request.Path())
+	if err != nil {
+		return nil, fmt.Errorf("unknown facet column %s", request.Path())
+	}
+
+	if request.OrderBy() != tableaux.FacetOrderCount && request.OrderBy() != tableaux.FacetOrderValue {
+		return nil, fmt.Errorf("unknown facet order %s", request.OrderBy())
+	}
+
+	var search globalSearch
+	if request.Request().GlobalSearch() != "" {
+		search = globalSearch{term: request.Request().GlobalSearch(), columns: columns, locale: request.Request().Locale()}
+	}
+
+	queryString, arguments, err := th.facetQuery(request, column, search, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	facetResult := datasource.FacetResult{}
+
+	observation := th.observeQuery(ctx, QueryFacets, schema.OriginalSchema().Entity, queryString, arguments.Values())
+	defer func() {
+		observation.finish(uint64(len(facetResult)), err)
+	}()
+
+	statement, err := th.dbConnector.DatabaseObject().PrepareContext(ctx, queryString)
+	if err != nil {
+		log.WithField("query", queryString).Error("Failed to prepare query")
+		return nil, err
+	}
+
+	defer statement.Close()
+
+	log.WithFields(
+		"query", queryString,
+		"arguments", arguments.Values(),
+	).Debug("Executing query")
+
+	rows, err := statement.QueryContext(ctx, arguments.Values()...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.LoggingRowsCloser(rows, "facets")
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw [2][]byte
+	dest := []interface{}{&raw[0], &raw[1]}
+
+	// Only enum columns have translation keys
+	enum, enumErr := th.enumMapper.Enum(column.Type)
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		value, err := th.dbConnector.MakeItemTypeSafe(raw[0], types[0])
+		if err != nil {
+			return nil, err
+		}
+
+		count, err := th.dbConnector.MakeItemTypeSafe(raw[1], types[1])
+		if err != nil {
+			return nil, err
+		}
+
+		typedCount, err := typedAggregateValue(tableaux.AggregateCount, "", count)
+		if err != nil {
+			return nil, err
+		}
+
+		facetValue := datasource.FacetValue{
+			Value: value,
+			Count: typedCount.(uint64),
+		}
+
+		if key, isKey := value.(string); isKey && enumErr == nil {
+			facetValue.TranslationKey, err = enum.TranslationKey(key)
+			if err != nil {
+				log.WithFields("enum", column.Type, "key", key).Warn("Facet value is unknown to enum")
+			}
+		}
+
+		facetResult = append(facetResult, facetValue)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	log.WithFields(
+		"time", time.Since(start),
+		"path", request.Path(),
+		"count", len(facetResult),
+	).Info("Facets fetched")
+
+	return &facetResult, nil
+}
+
+// Constructs the query, which groups the rows by the facet column, and counts each group.
+func (th Connector) facetQuery(request datasource.FacetRequest, column config.TableSchemaColumn, search globalSearch,
+	schema config.ResolvedTableSchema) (string, *Arguments, error) {
+	queryBuilder := th.dbConnector.QueryBuilder()
+	filterExpression := request.Request().FilterExpression()
+
+	joinString, err := th.resolveJoinString(mergeColumns([]config.TableSchemaColumn{column}, search.columns),
+		[]datasource.Order{}, schema, filterExpression)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resolvedPath := th.resolvers[column.PathResolver].ResolvePathName(column)
+
+	// The query parts are assembled in order of appearance, so the bound arguments line up
+	arguments := NewArguments(queryBuilder)
+
+	queryString := resolvedPath + " AS " + column.Path + ",COUNT(*) AS " + facetCountAlias +
+		" FROM " + schema.OriginalSchema().Entity
+	if joinString != "" {
+		queryString += " " + joinString
+	}
+
+	environment, err := th.environment(request.Request().TimeZone(), request.Request().Locale())
+	if err != nil {
+		return "", nil, err
+	}
+
+	whereString, err := th.whereString(filterExpression, search, environment, schema, arguments)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if whereString != "" {
+		queryString += " WHERE " + whereString
+	}
+
+	queryString += " GROUP BY " + resolvedPath
+
+	// Values are ordered by the sorter of the column (e.g. by translation for enums), and
+	// also break ties when ordering by count, so the result is stable
+	valueOrder := datasource.NewOrder(column.Path, request.Direction(), nil)
+	if request.OrderBy() == tableaux.FacetOrderCount {
+		valueOrder = datasource.NewOrder(column.Path, tableaux.OrderAsc, nil)
+	}
+
+	valueSortColumn, err := OrderColumn(queryBuilder, resolvedPath, column, th.sorters[column.Order], valueOrder, request.Request().Locale(), arguments)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sortColumns := []string{valueSortColumn}
+
+	if request.OrderBy() == tableaux.FacetOrderCount {
+		sortColumns = append([]string{queryBuilder.OrderColumn(facetCountAlias, request.Direction())}, sortColumns...)
+	}
+
+	queryString += " ORDER BY " + strings.Join(sortColumns, ",")
+
+	if request.Limit() > 0 {
+		queryString = queryBuilder.SelectWithLimitQuery(queryString, arguments.Bind(request.Limit()), "")
+	} else {
+		queryString = "SELECT " + queryString
+	}
+
+	return queryString, arguments, nil
+}
@@ -1,33 +1,31 @@
 package filter
 
 import (
+	"fmt"
 	"strings"
+
+	"github.com/tableaux-project/tableaux"
 )
 
 type Boolean struct {
 	*Common
 }
 
-func (filter Boolean) ParseValue(value interface{}) string {
+func (filter Boolean) ParseValue(value interface{}) (interface{}, error) {
 	boolean, canCast := value.(bool)
 	if canCast {
-		if boolean {
-			return "true"
-		}
-
-		return "false"
+		return boolean, nil
 	}
 
 	booleanString, canCast := value.(string)
 	if canCast {
-		boolean := booleanString == "1" || strings.ToLower(booleanString) == "true"
-
-		if boolean {
-			return "true"
-		}
-
-		return "false"
+		return booleanString == "1" || strings.ToLower(booleanString) == "true", nil
 	}
 
-	panic("todo - cannot parse value!")
+	return nil, fmt.Errorf("cannot parse value %v as boolean", value)
+}
+
+// Operator supports all filter modes, except for matching substrings.
+func (filter Boolean) Operator(_ interface{}, filterMode tableaux.FilterMode) (Operator, error) {
+	return operatorOf(filterMode, valueModes)
 }
@@ -6,24 +6,62 @@ import (
 	"github.com/tableaux-project/tableaux"
 )
 
+// The operators of all filter modes. IN is expressed as multiple values for OperatorEqual.
+var modeOperators = map[tableaux.FilterMode]Operator{
+	tableaux.FilterEquals:        OperatorEqual,
+	tableaux.FilterNotEquals:     OperatorNotEqual,
+	tableaux.FilterGreater:       OperatorGreater,
+	tableaux.FilterGreaterEquals: OperatorGreaterEquals,
+	tableaux.FilterLesser:        OperatorLesser,
+	tableaux.FilterLesserEquals:  OperatorLesserEquals,
+	tableaux.FilterIn:            OperatorEqual,
+	tableaux.FilterBetween:       OperatorBetween,
+	tableaux.FilterIsNull:        OperatorIsNull,
+	tableaux.FilterIsNotNull:     OperatorIsNotNull,
+	tableaux.FilterContains:      OperatorContains,
+	tableaux.FilterStartsWith:    OperatorStartsWith,
+	tableaux.FilterEndsWith:      OperatorEndsWith,
+}
+
+// The filter modes of columns, which are compared by value rather than as text.
+var valueModes = []tableaux.FilterMode{
+	tableaux.FilterEquals, tableaux.FilterNotEquals, tableaux.FilterGreater, tableaux.FilterGreaterEquals,
+	tableaux.FilterLesser, tableaux.FilterLesserEquals, tableaux.FilterIn, tableaux.FilterBetween,
+	tableaux.FilterIsNull, tableaux.FilterIsNotNull,
+}
+
+// Common determinates the Operator of all filter modes.
 type Common struct {
 }
 
 func (filter Common) Operator(_ interface{}, filterMode tableaux.FilterMode) (Operator, error) {
-	switch filterMode {
-	case tableaux.FilterEquals:
-		return OperatorEqual, nil
-	case tableaux.FilterGreater:
-		return OperatorGreater, nil
-	case tableaux.FilterGreaterEquals:
-		return OperatorGreaterEquals, nil
-	case tableaux.FilterLesser:
-		return OperatorLesser, nil
-	case tableaux.FilterLesserEquals:
-		return OperatorLesserEquals, nil
-	case tableaux.FilterNotEquals:
-		return OperatorNotEqual, nil
-	default:
+	operator, exists := modeOperators[filterMode]
+	if !exists {
 		return "", fmt.Errorf("unknown filter mode %s", filterMode)
 	}
+
+	return operator, nil
+}
+
+// Determinates the Operator of a filter mode, which must be one of the supported modes.
+func operatorOf(filterMode tableaux.FilterMode, supportedModes []tableaux.FilterMode) (Operator, error) {
+	for _, supportedMode := range supportedModes {
+		if supportedMode == filterMode {
+			return Common{}.Operator(nil, filterMode)
+		}
+	}
+
+	return "", fmt.Errorf("unsupported filter mode %s", filterMode)
+}
+
+// SupportedModes returns the filter modes, which the filter supports regardless of the value.
+func SupportedModes(filter Filter) []tableaux.FilterMode {
+	var supportedModes []tableaux.FilterMode
+	for _, filterMode := range tableaux.FilterModes() {
+		if _, err := filter.Operator(nil, filterMode); err == nil {
+			supportedModes = append(supportedModes, filterMode)
+		}
+	}
+
+	return supportedModes
 }
@@ -0,0 +1,95 @@
+package filter
+
+import (
+	"time"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04:05"
+)
+
+// RangeFilter is implemented by filters, of which each value matches a range of column values,
+// rather than a single one - e.g. a date, which matches the whole day. The filter modes are
+// applied to the range: EQUALS matches the range, GREATER matches after the range, and so on.
+type RangeFilter interface {
+	Filter
+
+	// Range converts a raw filter value into the range of column values it matches, within the
+	// environment of the request.
+	Range(value interface{}, environment dates.Environment) (Range, error)
+}
+
+// Range is the half-open range [From, To) of column values, which a filter value matches.
+type Range struct {
+	From interface{}
+	To   interface{}
+}
+
+// Date filters date columns. Values are resolved as of dates.DateRange, so each value matches
+// its whole day in the time zone of the request, or its whole span of days.
+type Date struct {
+	*Common
+}
+
+func (filter Date) ParseValue(value interface{}) (interface{}, error) {
+	dateRange, err := filter.Range(value, dates.NewEnvironment(time.UTC, "", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return dateRange.From, nil
+}
+
+// Operator supports all filter modes, except for matching substrings.
+func (filter Date) Operator(_ interface{}, filterMode tableaux.FilterMode) (Operator, error) {
+	return operatorOf(filterMode, valueModes)
+}
+
+func (filter Date) Range(value interface{}, environment dates.Environment) (Range, error) {
+	from, to, err := dates.DateRange(value, environment)
+	if err != nil {
+		return Range{}, err
+	}
+
+	return Range{
+		From: from.Format(dateLayout),
+		To:   to.Format(dateLayout),
+	}, nil
+}
+
+// DateTime filters date time columns, which are expected to hold UTC. Values are resolved as of
+// dates.DateTimeRange, so date times match their whole second, and dates and relative dates
+// their whole days in the time zone of the request.
+type DateTime struct {
+	*Common
+}
+
+func (filter DateTime) ParseValue(value interface{}) (interface{}, error) {
+	dateTimeRange, err := filter.Range(value, dates.NewEnvironment(time.UTC, "", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	return dateTimeRange.From, nil
+}
+
+// Operator supports all filter modes, except for matching substrings.
+func (filter DateTime) Operator(_ interface{}, filterMode tableaux.FilterMode) (Operator, error) {
+	return operatorOf(filterMode, valueModes)
+}
+
+func (filter DateTime) Range(value interface{}, environment dates.Environment) (Range, error) {
+	from, to, err := dates.DateTimeRange(value, environment)
+	if err != nil {
+		return Range{}, err
+	}
+
+	return Range{
+		From: from.UTC().Format(dateTimeLayout),
+		To:   to.UTC().Format(dateTimeLayout),
+	}, nil
+}
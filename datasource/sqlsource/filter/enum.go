@@ -0,0 +1,129 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+)
+
+// Enum is a filter for enum columns. Enum values are filtered by their enum keys. Once bound to
+// a column, values are validated against the enum of the column, and may also be given as the
+// translation of an enum key in the locale of the request.
+type Enum struct {
+	*Common
+
+	mapper     config.EnumMapper
+	translator config.Translator
+
+	// The enum of the bound column, and the locale of the request
+	enum   string
+	locale string
+}
+
+// NewEnum creates a new Enum filter instance.
+func NewEnum(mapper config.EnumMapper, translator config.Translator) Enum {
+	return Enum{
+		Common:     &Common{},
+		mapper:     mapper,
+		translator: translator,
+	}
+}
+
+// ForColumn binds the filter to the enum of the column, and to the locale of the request.
+func (filter Enum) ForColumn(column config.TableSchemaColumn, environment dates.Environment) Filter {
+	filter.enum = column.Type
+	filter.locale = environment.Locale
+
+	return filter
+}
+
+// ParseValue converts a value into an enum key. An unbound filter accepts any string as enum key.
+// A bound filter accepts the keys of its enum, and their translations in the locale of the
+// request (case insensitive), which are converted into their keys.
+func (filter Enum) ParseValue(value interface{}) (interface{}, error) {
+	stringVal, canCast := value.(string)
+	if !canCast {
+		return nil, fmt.Errorf("cannot parse value %v as enum key", value)
+	}
+
+	if filter.enum == "" {
+		return stringVal, nil
+	}
+
+	enum, err := filter.mapper.Enum(filter.enum)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err, filter.enum)
+	}
+
+	if _, err := enum.TranslationKey(stringVal); err == nil {
+		return stringVal, nil
+	}
+
+	var keys []string
+	for _, entry := range sortedEntries(enum) {
+		translation, err := filter.translator.Translate(filter.locale, entry.TranslationKey)
+		if err == nil && strings.EqualFold(translation, stringVal) {
+			keys = append(keys, entry.EnumKey)
+		}
+	}
+
+	switch len(keys) {
+	case 0:
+		return nil, fmt.Errorf("%s is neither a key nor a label of enum %s", stringVal, filter.enum)
+	case 1:
+		return keys[0], nil
+	default:
+		return nil, fmt.Errorf("label %s of enum %s is ambiguous, it matches the keys %s",
+			stringVal, filter.enum, strings.Join(keys, ", "))
+	}
+}
+
+// The filter modes of enums. Enum keys have no meaningful order, and are not matched as text.
+var enumModes = []tableaux.FilterMode{
+	tableaux.FilterEquals, tableaux.FilterNotEquals, tableaux.FilterIn, tableaux.FilterIsNull, tableaux.FilterIsNotNull,
+}
+
+// Operator only supports equality, IN and null checks, as enum keys have no meaningful order.
+func (filter Enum) Operator(_ interface{}, filterMode tableaux.FilterMode) (Operator, error) {
+	return operatorOf(filterMode, enumModes)
+}
+
+// Search matches all enum keys, of which either the key itself or its translation in the
+// given locale contains the search term (case insensitive).
+func (filter Enum) Search(term string, column config.TableSchemaColumn, locale string) (Operator, []interface{}) {
+	enum, err := filter.mapper.Enum(column.Type)
+	if err != nil {
+		return "", nil
+	}
+
+	lowerTerm := strings.ToLower(term)
+
+	var keys []interface{}
+	for _, entry := range sortedEntries(enum) {
+		if strings.Contains(strings.ToLower(entry.EnumKey), lowerTerm) {
+			keys = append(keys, entry.EnumKey)
+			continue
+		}
+
+		translation, err := filter.translator.Translate(locale, entry.TranslationKey)
+		if err == nil && strings.Contains(strings.ToLower(translation), lowerTerm) {
+			keys = append(keys, entry.EnumKey)
+		}
+	}
+
+	return OperatorEqual, keys
+}
+
+// Returns the entries of the enum sorted by key, so resulting queries and errors are stable.
+func sortedEntries(enum config.Enum) []config.KeyWithTranslation {
+	entries := enum.Entries()
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].EnumKey < entries[j].EnumKey
+	})
+
+	return entries
+}
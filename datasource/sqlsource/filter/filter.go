@@ -1,7 +1,11 @@
 package filter
 
 import (
+	"strings"
+
 	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource/dates"
 )
 
 type Operator string
@@ -11,13 +15,75 @@ const (
 	OperatorNotLike       Operator = "NOT LIKE"
 	OperatorEqual         Operator = "="
 	OperatorNotEqual      Operator = "!="
-	OperatorGreater       Operator = "GREATER"
-	OperatorGreaterEquals Operator = "GREATER_EQUALS"
-	OperatorLesser        Operator = "LESSER"
-	OperatorLesserEquals  Operator = "LESSER_EQUALS"
+	OperatorGreater       Operator = ">"
+	OperatorGreaterEquals Operator = ">="
+	OperatorLesser        Operator = "<"
+	OperatorLesserEquals  Operator = "<="
+	OperatorIsNull        Operator = "IS NULL"
+	OperatorIsNotNull     Operator = "IS NOT NULL"
+
+	// Operators, which a QueryBuilder has to translate. OperatorBetween expects a lower and an
+	// upper bound, the others expect a term, which is converted into a LIKE pattern.
+	OperatorBetween    Operator = "BETWEEN"
+	OperatorContains   Operator = "CONTAINS"
+	OperatorStartsWith Operator = "STARTS_WITH"
+	OperatorEndsWith   Operator = "ENDS_WITH"
 )
 
+// Filter converts raw filter values into values which can be bound as query
+// arguments, and determinates the Operator for a given FilterMode.
 type Filter interface {
-	ParseValue(value interface{}) string
+	// ParseValue converts a raw filter value into a value which can be bound as
+	// an argument of a prepared query. Values must never be inlined into the query
+	// string itself, so there is no need to quote or escape anything here.
+	ParseValue(value interface{}) (interface{}, error)
 	Operator(value interface{}, filterMode tableaux.FilterMode) (Operator, error)
 }
+
+// ColumnFilter is implemented by filters, of which the accepted values depend on the filtered
+// column or on the request, e.g. enum filters, which accept the keys of the enum of the column.
+type ColumnFilter interface {
+	Filter
+
+	// ForColumn returns the filter bound to the column, and to the environment of the request.
+	ForColumn(column config.TableSchemaColumn, environment dates.Environment) Filter
+}
+
+// Searcher is implemented by filters which can take part in the global search. A Searcher
+// converts the search term into the Operator and values to filter a single column by.
+type Searcher interface {
+	// Search returns the Operator and values which match the search term on the given column.
+	// If the term cannot possibly match the column (e.g. a non numeric term on a numeric column),
+	// no values are returned, and the column does not take part in the search.
+	Search(term string, column config.TableSchemaColumn, locale string) (Operator, []interface{})
+}
+
+// LikeEscape is the escape character of LIKE patterns. Unlike a backslash, it needs no escaping
+// in string literals of any database, so the ESCAPE clause declaring it is portable.
+const LikeEscape = "!"
+
+// Escapes the wildcards of LIKE in a term by LikeEscape, so they are matched literally.
+var likeEscaper = strings.NewReplacer(LikeEscape, LikeEscape+LikeEscape, "%", LikeEscape+"%", "_", LikeEscape+"_")
+
+// LikePattern converts a term into the LIKE pattern of OperatorContains, OperatorStartsWith or
+// OperatorEndsWith. Wildcards in the term are escaped by LikeEscape, so they are matched
+// literally. For any other operator, the escaped term itself is returned.
+func LikePattern(operator Operator, term string) string {
+	escaped := likeEscaper.Replace(term)
+
+	switch operator {
+	case OperatorContains:
+		return "%" + escaped + "%"
+	case OperatorStartsWith:
+		return escaped + "%"
+	case OperatorEndsWith:
+		return "%" + escaped
+	default:
+		return escaped
+	}
+}
+
+// Checks if the column holds plain strings, which can be searched via LIKE.
+func isStringColumn(column config.TableSchemaColumn) bool {
+	return strings.ToLower(column.Type) == "string"
+}
@@ -0,0 +1,364 @@
+package filter
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+)
+
+func TestSearch(t *testing.T) {
+	stringColumn := config.TableSchemaColumn{Path: "person_name", Type: "string"}
+	dateColumn := config.TableSchemaColumn{Path: "person_birthday", Type: "date"}
+
+	tables := []struct {
+		searcher Searcher
+		column   config.TableSchemaColumn
+		term     string
+		operator Operator
+		values   []interface{}
+	}{
+		{PlainString{}, stringColumn, "Bob", OperatorContains, []interface{}{"Bob"}},
+		{PlainString{}, stringColumn, `100%_\`, OperatorContains, []interface{}{`100%_\`}},
+		{PlainString{}, dateColumn, "2018", "", nil},
+		{RegexString{}, stringColumn, "Bob.*", OperatorContains, []interface{}{"Bob.*"}},
+		{Numeric{}, stringColumn, "42", OperatorEqual, []interface{}{int64(42)}},
+		{Numeric{}, stringColumn, "Bob", "", nil},
+	}
+
+	for _, table := range tables {
+		operator, values := table.searcher.Search(table.term, table.column, "de")
+
+		if operator != table.operator || !reflect.DeepEqual(values, table.values) {
+			t.Errorf("%T.Search(%s) was incorrect, got: %s %v, want: %s %v.", table.searcher, table.term,
+				operator, values, table.operator, table.values)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Loading location failed: %s", err)
+	}
+
+	dateFilter := Date{Common: &Common{}}
+	dateTimeFilter := DateTime{Common: &Common{}}
+
+	tables := []struct {
+		filter   RangeFilter
+		value    interface{}
+		location *time.Location
+		want     Range
+	}{
+		{dateFilter, "2018-03-24", time.UTC, Range{"2018-03-24", "2018-03-25"}},
+		{dateFilter, "2018-03-24", berlin, Range{"2018-03-24", "2018-03-25"}},
+		{dateFilter, "2018-03-24T23:30:00Z", time.UTC, Range{"2018-03-24", "2018-03-25"}},
+		{dateFilter, "2018-03-24T23:30:00Z", berlin, Range{"2018-03-25", "2018-03-26"}},
+		{dateFilter, time.Date(2018, 12, 31, 12, 0, 0, 0, time.UTC), time.UTC, Range{"2018-12-31", "2019-01-01"}},
+		{dateTimeFilter, "2018-03-24T12:30:15Z", berlin, Range{"2018-03-24 12:30:15", "2018-03-24 12:30:16"}},
+		{dateTimeFilter, "2018-03-24T12:30:15.75+02:00", time.UTC, Range{"2018-03-24 10:30:15", "2018-03-24 10:30:16"}},
+		{dateTimeFilter, "2018-03-24T12:30:15", berlin, Range{"2018-03-24 11:30:15", "2018-03-24 11:30:16"}},
+		{dateTimeFilter, "2018-03-24 12:30", time.UTC, Range{"2018-03-24 12:30:00", "2018-03-24 12:30:01"}},
+		{dateTimeFilter, "2018-03-24", time.UTC, Range{"2018-03-24 00:00:00", "2018-03-25 00:00:00"}},
+		// The switch to daylight saving time shortens the day to 23 hours
+		{dateTimeFilter, "2018-03-25", berlin, Range{"2018-03-24 23:00:00", "2018-03-25 22:00:00"}},
+	}
+
+	for _, table := range tables {
+		got, err := table.filter.Range(table.value, dates.NewEnvironment(table.location, "", nil))
+		if err != nil {
+			t.Errorf("%T.Range(%v, %s) failed: %s", table.filter, table.value, table.location, err)
+			continue
+		}
+
+		if got != table.want {
+			t.Errorf("%T.Range(%v, %s) was incorrect, got: %v, want: %v.", table.filter, table.value, table.location, got, table.want)
+		}
+	}
+}
+
+func TestRangeInvalid(t *testing.T) {
+	filters := []RangeFilter{Date{Common: &Common{}}, DateTime{Common: &Common{}}}
+	values := []interface{}{"", "someday", "2018-13-01", "24.03.2018", 20180324, nil}
+
+	for _, filter := range filters {
+		for _, value := range values {
+			if _, err := filter.Range(value, dates.NewEnvironment(time.UTC, "", nil)); err == nil {
+				t.Errorf("%T.Range(%v) should have failed.", filter, value)
+			}
+
+			if _, err := filter.ParseValue(value); err == nil {
+				t.Errorf("%T.ParseValue(%v) should have failed.", filter, value)
+			}
+		}
+	}
+}
+
+func TestRelativeRange(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Loading location failed: %s", err)
+	}
+
+	// A wednesday, which already is thursday in Berlin
+	clock := func() time.Time {
+		return time.Date(2018, 3, 21, 23, 30, 0, 0, time.UTC)
+	}
+
+	dateFilter := Date{Common: &Common{}}
+	dateTimeFilter := DateTime{Common: &Common{}}
+
+	tables := []struct {
+		filter      RangeFilter
+		value       string
+		environment dates.Environment
+		want        Range
+	}{
+		{dateFilter, "today", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-03-21", "2018-03-22"}},
+		{dateFilter, "yesterday", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-03-20", "2018-03-21"}},
+		{dateFilter, "tomorrow", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-03-22", "2018-03-23"}},
+		{dateFilter, "today", dates.NewEnvironment(berlin, "de", clock), Range{"2018-03-22", "2018-03-23"}},
+		{dateFilter, "this week", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-03-19", "2018-03-26"}},
+		{dateFilter, "this week", dates.NewEnvironment(time.UTC, "en", clock), Range{"2018-03-18", "2018-03-25"}},
+		{dateFilter, "last week", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-03-12", "2018-03-19"}},
+		{dateFilter, "next month", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-04-01", "2018-05-01"}},
+		{dateFilter, "this quarter", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-01-01", "2018-04-01"}},
+		{dateFilter, "last quarter", dates.NewEnvironment(time.UTC, "de", clock), Range{"2017-10-01", "2018-01-01"}},
+		{dateFilter, "next year", dates.NewEnvironment(time.UTC, "de", clock), Range{"2019-01-01", "2020-01-01"}},
+		{dateFilter, "last 7 days", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-03-15", "2018-03-22"}},
+		{dateFilter, " Next  2 Weeks", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-03-21", "2018-04-04"}},
+		{dateFilter, "last 1 month", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-02-22", "2018-03-22"}},
+		{dateTimeFilter, "today", dates.NewEnvironment(time.UTC, "de", clock), Range{"2018-03-21 00:00:00", "2018-03-22 00:00:00"}},
+		{dateTimeFilter, "today", dates.NewEnvironment(berlin, "de", clock), Range{"2018-03-21 23:00:00", "2018-03-22 23:00:00"}},
+		// The switch to daylight saving time lies within the week
+		{dateTimeFilter, "this week", dates.NewEnvironment(berlin, "de", clock), Range{"2018-03-18 23:00:00", "2018-03-25 22:00:00"}},
+	}
+
+	for _, table := range tables {
+		got, err := table.filter.Range(table.value, table.environment)
+		if err != nil {
+			t.Errorf("%T.Range(%s) failed: %s", table.filter, table.value, err)
+			continue
+		}
+
+		if got != table.want {
+			t.Errorf("%T.Range(%s) was incorrect, got: %v, want: %v.", table.filter, table.value, got, table.want)
+		}
+	}
+
+	for _, value := range []string{"last 0 days", "this fortnight", "next days"} {
+		if _, err := dateFilter.Range(value, dates.NewEnvironment(time.UTC, "de", clock)); err == nil {
+			t.Errorf("Date.Range(%s) should have failed.", value)
+		}
+	}
+}
+
+func newTestEnum(t *testing.T) Enum {
+	enumMapper, err := config.NewEnumMapperFromFolder(filepath.Join("testfiles", "enum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translator, err := config.NewTranslatorFromFolder(filepath.Join("testfiles", "i18n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewEnum(enumMapper, translator)
+}
+
+func TestEnumParseValue(t *testing.T) {
+	enumFilter := newTestEnum(t)
+	countryColumn := config.TableSchemaColumn{Path: "person_country", Type: "country"}
+
+	tables := []struct {
+		filter Filter
+		value  interface{}
+		want   interface{}
+	}{
+		{enumFilter, "XX", "XX"},
+		{enumFilter.ForColumn(countryColumn, dates.Environment{Locale: "en"}), "DE", "DE"},
+		{enumFilter.ForColumn(countryColumn, dates.Environment{Locale: "en"}), "Germany", "DE"},
+		{enumFilter.ForColumn(countryColumn, dates.Environment{Locale: "en"}), "switzerland", "CH"},
+		{enumFilter.ForColumn(countryColumn, dates.Environment{Locale: "de"}), "Österreich", "AT"},
+	}
+
+	for _, table := range tables {
+		got, err := table.filter.ParseValue(table.value)
+		if err != nil {
+			t.Errorf("ParseValue(%v) failed: %s", table.value, err)
+			continue
+		}
+
+		if got != table.want {
+			t.Errorf("ParseValue(%v) was incorrect, got: %v, want: %v.", table.value, got, table.want)
+		}
+	}
+
+	invalid := []struct {
+		filter Filter
+		value  interface{}
+	}{
+		{enumFilter, 42},
+		{enumFilter.ForColumn(countryColumn, dates.Environment{Locale: "en"}), "XX"},
+		// Labels are only resolved in the locale of the request
+		{enumFilter.ForColumn(countryColumn, dates.Environment{Locale: "en"}), "Deutschland"},
+		{enumFilter.ForColumn(config.TableSchemaColumn{Path: "person_planet", Type: "planet"}, dates.Environment{Locale: "en"}), "Earth"},
+	}
+
+	for _, table := range invalid {
+		if _, err := table.filter.ParseValue(table.value); err == nil {
+			t.Errorf("ParseValue(%v) should have failed.", table.value)
+		}
+	}
+}
+
+func TestEnumOperator(t *testing.T) {
+	enumFilter := newTestEnum(t)
+
+	if _, err := enumFilter.Operator("DE", tableaux.FilterNotEquals); err != nil {
+		t.Errorf("Operator(%s) failed: %s", tableaux.FilterNotEquals, err)
+	}
+
+	if _, err := enumFilter.Operator("DE", tableaux.FilterGreater); err == nil {
+		t.Errorf("Operator(%s) should have failed.", tableaux.FilterGreater)
+	}
+}
+
+func TestNumericParseValue(t *testing.T) {
+	numericFilter := Numeric{Common: &Common{}}
+	bind := func(columnType string) Filter {
+		return numericFilter.ForColumn(config.TableSchemaColumn{Path: "product_price", Type: columnType}, dates.Environment{})
+	}
+
+	tables := []struct {
+		filter Filter
+		value  interface{}
+		want   interface{}
+	}{
+		{numericFilter, "42", int64(42)},
+		{numericFilter, "-42", int64(-42)},
+		{numericFilter, "18446744073709551615", uint64(18446744073709551615)},
+		{bind("integer"), float64(42), int64(42)},
+		{bind("long"), uint64(7), uint64(7)},
+		{bind("float"), "4.2", 4.2},
+		{bind("float"), "1e3", float64(1000)},
+		{bind("float"), int64(42), float64(42)},
+		{bind("decimal"), "12345678901234567890.123456789", "12345678901234567890.123456789"},
+		{bind("decimal"), "-0.10", "-0.10"},
+		{bind("Decimal"), 4.2, "4.2"},
+		{bind("decimal"), int64(42), "42"},
+		{bind("decimal"), json.Number("12345678901234567890.123456789"), "12345678901234567890.123456789"},
+		{bind("decimal"), json.Number("1.5e2"), "150"},
+		{bind("float"), json.Number("4.2"), 4.2},
+		{bind("long"), json.Number("42"), int64(42)},
+	}
+
+	for _, table := range tables {
+		got, err := table.filter.ParseValue(table.value)
+		if err != nil {
+			t.Errorf("ParseValue(%v) failed: %s", table.value, err)
+			continue
+		}
+
+		if got != table.want {
+			t.Errorf("ParseValue(%v) was incorrect, got: %v (%T), want: %v (%T).", table.value, got, got, table.want, table.want)
+		}
+	}
+
+	invalid := []struct {
+		filter Filter
+		value  interface{}
+	}{
+		{numericFilter, "abc"},
+		{numericFilter, ""},
+		{numericFilter, "4.2"},
+		{numericFilter, 4.2},
+		{numericFilter, json.Number("4.2")},
+		{numericFilter, true},
+		{bind("float"), "NaN"},
+		{bind("float"), "4,2"},
+		{bind("decimal"), "1e3"},
+		{bind("decimal"), "12.3.4"},
+		{bind("decimal"), "0x10"},
+	}
+
+	for _, table := range invalid {
+		if _, err := table.filter.ParseValue(table.value); err == nil {
+			t.Errorf("ParseValue(%v) should have failed.", table.value)
+		}
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	tables := []struct {
+		operator Operator
+		term     string
+		want     string
+	}{
+		{OperatorContains, "Bob", "%Bob%"},
+		{OperatorContains, `100%_!\`, `%100!%!_!!\%`},
+		{OperatorStartsWith, "a_b", "a!_b%"},
+		{OperatorEndsWith, "50%", "%50!%"},
+		{OperatorEqual, "50%", "50!%"},
+	}
+
+	for _, table := range tables {
+		if got := LikePattern(table.operator, table.term); got != table.want {
+			t.Errorf("LikePattern(%s, %s) was incorrect, got: %s, want: %s.", table.operator, table.term, got, table.want)
+		}
+	}
+}
+
+func TestSupportedModes(t *testing.T) {
+	tables := []struct {
+		filter Filter
+		want   []tableaux.FilterMode
+	}{
+		{PlainString{Common: &Common{}}, tableaux.FilterModes()},
+		{RegexString{Common: &Common{}}, tableaux.FilterModes()},
+		{Numeric{Common: &Common{}}, valueModes},
+		{Date{Common: &Common{}}, valueModes},
+		{Enum{Common: &Common{}}, []tableaux.FilterMode{
+			tableaux.FilterEquals, tableaux.FilterNotEquals, tableaux.FilterIn, tableaux.FilterIsNull, tableaux.FilterIsNotNull,
+		}},
+	}
+
+	for _, table := range tables {
+		if got := SupportedModes(table.filter); !reflect.DeepEqual(got, table.want) {
+			t.Errorf("SupportedModes(%T) was incorrect, got: %v, want: %v.", table.filter, got, table.want)
+		}
+	}
+}
+
+func TestRegexStringParseValue(t *testing.T) {
+	regexFilter := RegexString{Common: &Common{}}
+
+	tables := []struct {
+		value string
+		want  string
+	}{
+		{"Bob", "Bob"},
+		{"50%", "50%"},
+		{"B.*b", "B%b"},
+		{".*50%_off.*", "%50!%!_off%"},
+	}
+
+	for _, table := range tables {
+		got, err := regexFilter.ParseValue(table.value)
+		if err != nil {
+			t.Errorf("ParseValue(%s) failed: %s", table.value, err)
+			continue
+		}
+
+		if got != table.want {
+			t.Errorf("ParseValue(%s) was incorrect, got: %v, want: %s.", table.value, got, table.want)
+		}
+	}
+}
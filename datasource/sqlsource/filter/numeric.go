@@ -1,29 +1,159 @@
 package filter
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
 	"strconv"
+	"strings"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource/dates"
 )
 
+// The literal of a decimal number, without an exponent
+var decimalLiteral = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// Numeric filters numeric columns. Once bound to a column, values are parsed according to the
+// type of the column: float columns are filtered by float64 values, and decimal columns by the
+// literal of the decimal number, so the database compares it without any loss of precision.
+// Integer and long columns, as well as an unbound filter, are filtered by integral values.
 type Numeric struct {
 	*Common
+
+	// The type of the bound column
+	columnType string
+}
+
+// ForColumn binds the filter to the type of the column.
+func (filter Numeric) ForColumn(column config.TableSchemaColumn, _ dates.Environment) Filter {
+	filter.columnType = strings.ToLower(column.Type)
+	return filter
+}
+
+func (filter Numeric) ParseValue(value interface{}) (interface{}, error) {
+	var parsed interface{}
+	var ok bool
+
+	switch filter.columnType {
+	case "float":
+		parsed, ok = parseFloat(value)
+	case "decimal":
+		parsed, ok = parseDecimal(value)
+	default:
+		parsed, ok = parseInteger(value)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("cannot parse value %v as number", value)
+	}
+
+	return parsed, nil
+}
+
+// Operator supports all filter modes, except for matching substrings.
+func (filter Numeric) Operator(_ interface{}, filterMode tableaux.FilterMode) (Operator, error) {
+	return operatorOf(filterMode, valueModes)
 }
 
-func (filter Numeric) ParseValue(value interface{}) string {
-	uint64Value, canCast := value.(uint64)
-	if canCast {
-		return strconv.FormatUint(uint64Value, 10)
+func (filter Numeric) Search(term string, column config.TableSchemaColumn, _ string) (Operator, []interface{}) {
+	value, err := filter.ForColumn(column, dates.Environment{}).ParseValue(term)
+	if err != nil {
+		return "", nil
 	}
 
-	int64Value, canCast := value.(int64)
-	if canCast {
-		return strconv.FormatInt(int64Value, 10)
+	return OperatorEqual, []interface{}{value}
+}
+
+// Parses an integral value into an int64, or an uint64 if it is too large.
+func parseInteger(value interface{}) (interface{}, bool) {
+	switch converted := value.(type) {
+	case int64, uint64:
+		return converted, true
+	case int:
+		return int64(converted), true
+	case float64:
+		if converted != math.Trunc(converted) || math.Abs(converted) >= math.MaxInt64 {
+			return nil, false
+		}
+
+		return int64(converted), true
+	case json.Number:
+		return parseInteger(string(converted))
+	case string:
+		if int64Value, err := strconv.ParseInt(converted, 10, 64); err == nil {
+			return int64Value, true
+		}
+
+		if uint64Value, err := strconv.ParseUint(converted, 10, 64); err == nil {
+			return uint64Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Parses a finite value into a float64.
+func parseFloat(value interface{}) (interface{}, bool) {
+	var parsed float64
+
+	switch converted := value.(type) {
+	case float64:
+		parsed = converted
+	case int64:
+		parsed = float64(converted)
+	case uint64:
+		parsed = float64(converted)
+	case int:
+		parsed = float64(converted)
+	case json.Number:
+		return parseFloat(string(converted))
+	case string:
+		var err error
+		if parsed, err = strconv.ParseFloat(converted, 64); err != nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	if math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+		return nil, false
 	}
 
-	stringValue, canCast := value.(string)
-	if canCast {
-		intValue, _ := strconv.ParseInt(stringValue, 10, 64)
-		return strconv.FormatInt(intValue, 10)
+	return parsed, true
+}
+
+// Parses a value into the literal of a decimal number. Strings and JSON numbers are taken as
+// they are, so they keep their precision, while numbers are formatted by their shortest exact
+// representation. JSON numbers with an exponent are formatted like floats.
+func parseDecimal(value interface{}) (interface{}, bool) {
+	switch converted := value.(type) {
+	case json.Number:
+		if decimalLiteral.MatchString(string(converted)) {
+			return string(converted), true
+		}
+
+		if float64Value, err := converted.Float64(); err == nil {
+			return parseDecimal(float64Value)
+		}
+	case string:
+		if decimalLiteral.MatchString(converted) {
+			return converted, true
+		}
+	case int64:
+		return strconv.FormatInt(converted, 10), true
+	case uint64:
+		return strconv.FormatUint(converted, 10), true
+	case int:
+		return strconv.Itoa(converted), true
+	case float64:
+		if !math.IsNaN(converted) && !math.IsInf(converted, 0) {
+			return strconv.FormatFloat(converted, 'f', -1, 64), true
+		}
 	}
 
-	panic("todo - cannot parse value!")
+	return nil, false
 }
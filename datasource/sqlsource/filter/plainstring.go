@@ -2,17 +2,27 @@ package filter
 
 import (
 	"fmt"
+
+	"github.com/tableaux-project/tableaux/config"
 )
 
 type PlainString struct {
 	*Common
 }
 
-func (filter PlainString) ParseValue(value interface{}) string {
+func (filter PlainString) ParseValue(value interface{}) (interface{}, error) {
 	stringVal, canCast := value.(string)
 	if canCast {
-		return fmt.Sprintf(`'%s'`, stringVal)
+		return stringVal, nil
+	}
+
+	return nil, fmt.Errorf("cannot parse value %v as string", value)
+}
+
+func (filter PlainString) Search(term string, column config.TableSchemaColumn, _ string) (Operator, []interface{}) {
+	if !isStringColumn(column) {
+		return "", nil
 	}
 
-	panic("todo - cannot parse value!")
+	return OperatorContains, []interface{}{term}
 }
@@ -1,33 +1,39 @@
 package filter
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
 )
 
 type RegexString struct {
 	*Common
 }
 
-func (filter RegexString) ParseValue(value interface{}) string {
+// ParseValue converts a value containing ".*" into a LIKE pattern, of which ".*" matches any
+// sequence of characters, while all other characters are matched literally.
+func (filter RegexString) ParseValue(value interface{}) (interface{}, error) {
 	stringVal, canCast := value.(string)
-	if canCast {
-		return fmt.Sprintf(`'%s'`, strings.Replace(stringVal, ".*", "%", -1))
+	if !canCast {
+		return nil, fmt.Errorf("cannot parse value %v as string", value)
 	}
 
-	panic("todo - cannot parse value!")
-}
+	if !strings.Contains(stringVal, ".*") {
+		return stringVal, nil
+	}
 
-func (filter RegexString) Operator(value interface{}, filterMode tableaux.FilterMode) (Operator, error) {
-	stringVal, canCast := value.(string)
-	if !canCast {
-		return "", errors.New("cannot cast to string")
+	parts := strings.Split(stringVal, ".*")
+	for i, part := range parts {
+		parts[i] = LikePattern("", part)
 	}
 
-	if strings.Contains(stringVal, ".*") {
+	return strings.Join(parts, "%"), nil
+}
+
+func (filter RegexString) Operator(value interface{}, filterMode tableaux.FilterMode) (Operator, error) {
+	if stringVal, canCast := value.(string); canCast && strings.Contains(stringVal, ".*") {
 		switch filterMode {
 		case tableaux.FilterEquals:
 			return OperatorLike, nil
@@ -36,7 +42,15 @@ func (filter RegexString) Operator(value interface{}, filterMode tableaux.Filter
 		default:
 			return filter.Common.Operator(value, filterMode)
 		}
-	} else {
-		return filter.Common.Operator(value, filterMode)
 	}
+
+	return filter.Common.Operator(value, filterMode)
+}
+
+func (filter RegexString) Search(term string, column config.TableSchemaColumn, _ string) (Operator, []interface{}) {
+	if !isStringColumn(column) {
+		return "", nil
+	}
+
+	return OperatorContains, []interface{}{term}
 }
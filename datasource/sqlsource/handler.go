@@ -1,8 +1,8 @@
 package sqlsource
 
 import (
+	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -13,6 +13,7 @@ import (
 	"github.com/tableaux-project/tableaux"
 	"github.com/tableaux-project/tableaux/config"
 	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
 	"github.com/tableaux-project/tableaux/datasource/sqlsource/filter"
 	"github.com/tableaux-project/tableaux/datasource/sqlsource/order"
 	"github.com/tableaux-project/tableaux/datasource/sqlsource/path"
@@ -28,245 +29,577 @@ type Connector struct {
 	resolvers    map[string]datasource.PathResolver
 	sorters      map[string]order.Sorter
 	filters      map[string]filter.Filter
+	clock        dates.Clock
+	flights      *flightGroup
+	observers    *observerRegistry
 }
 
-func NewConnector(databaseConnector DatabaseConnector, enumMapper config.EnumMapper, translator config.Translator, schemaMapper config.SchemaMapper) (datasource.Connector, error) {
+// NewConnector creates a new Connector for the given database. The built-in filters, orders
+// and path resolvers can be extended or replaced via options. All schemas must only reference
+// filters, orders and path resolvers, which are known to the Connector.
+func NewConnector(databaseConnector DatabaseConnector, enumMapper config.EnumMapper, translator config.Translator, schemaMapper config.SchemaMapper,
+	options ...Option) (datasource.Connector, error) {
 	if err := schemaMapper.ValidateIntegrity(enumMapper); err != nil {
 		return nil, err
 	}
 
-	return &Connector{
-		databaseConnector,
-		enumMapper,
-		schemaMapper,
-		translator,
-		map[string]datasource.PathResolver{
+	connector := &Connector{
+		dbConnector:  databaseConnector,
+		enumMapper:   enumMapper,
+		schemaMapper: schemaMapper,
+		translator:   translator,
+		resolvers: map[string]datasource.PathResolver{
 			"":                 path.SimpleResolver{},
 			"SizePathResolver": path.SizeResolver{},
 		},
-		map[string]order.Sorter{
+		sorters: map[string]order.Sorter{
 			"":               order.Direct{},
 			"EnumOrder":      order.NewEnumSorter(enumMapper, translator),
 			"ShortEnumOrder": order.NewShortEnumSorter(enumMapper, translator),
 			"LongEnumOrder":  order.NewLongEnumSorter(enumMapper, translator),
 		},
-		map[string]filter.Filter{
+		filters: map[string]filter.Filter{
 			"BooleanFilter":     filter.Boolean{Common: &filter.Common{}},
 			"StringFilter":      filter.PlainString{Common: &filter.Common{}},
 			"StringRegExFilter": filter.RegexString{Common: &filter.Common{}},
-			"EnumFilter":        filter.PlainString{Common: &filter.Common{}}, // TODO
-			"NumericFilter":     filter.Numeric{Common: &filter.Common{}},     // TODO
-			"DateFilter":        filter.PlainString{Common: &filter.Common{}}, // TODO
-			"DateTimeFilter":    filter.PlainString{Common: &filter.Common{}}, // TODO
+			"EnumFilter":        filter.NewEnum(enumMapper, translator),
+			"NumericFilter":     filter.Numeric{Common: &filter.Common{}},
+			"DateFilter":        filter.Date{Common: &filter.Common{}},
+			"DateTimeFilter":    filter.DateTime{Common: &filter.Common{}},
 		},
-	}, nil
+		clock:     time.Now,
+		flights:   newFlightGroup(),
+		observers: &observerRegistry{},
+	}
+
+	for _, option := range options {
+		option(connector)
+	}
+
+	if err := schemaMapper.ValidateReferences(connector.references()); err != nil {
+		return nil, err
+	}
+
+	return connector, nil
+}
+
+func (th Connector) ValidateRequest(request datasource.Request) error {
+	return th.ValidateRequestContext(context.Background(), request)
 }
 
-func (th Connector) ValidateRequest(columns []config.TableSchemaColumn, schema config.ResolvedTableSchema,
-	filters []datasource.FilterGroup, orders []datasource.Order, globalSearch string, limit, offset uint64,
-	locale string) error {
-	if len(columns) == 0 {
-		return errors.New("no columns selected")
+// ValidateRequestContext validates the request in a single pass. All problems found are
+// returned as datasource.ValidationErrors.
+func (th Connector) ValidateRequestContext(ctx context.Context, request datasource.Request) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	if _, err := th.translator.Language(locale); err != nil {
-		return fmt.Errorf("unknown locale %s", locale)
+	var errs datasource.ValidationErrors
+
+	if len(request.Columns()) == 0 {
+		errs.Add(datasource.ValidationInvalidRequest, datasource.CodeNoColumns, "", "no columns selected")
 	}
 
-	for _, column := range columns {
-		columnPath := column.Path
+	schema, err := th.schemaMapper.ResolvedSchema(request.Schema())
+	if err != nil {
+		errs.Add(datasource.ValidationUnknownSchema, datasource.CodeUnknownSchema, "", "unknown schema %s", request.Schema())
+		return errs
+	}
+
+	if _, err := th.translator.Language(request.Locale()); err != nil {
+		errs.Add(datasource.ValidationUnknownLocale, datasource.CodeUnknownLocale, "", "unknown locale %s", request.Locale())
+	}
+
+	environment, err := th.environment(request.TimeZone(), request.Locale())
+	if err != nil {
+		errs.Add(datasource.ValidationUnknownTimeZone, datasource.CodeUnknownTimeZone, "", "unknown time zone %s", request.TimeZone())
+		environment = dates.NewEnvironment(time.UTC, request.Locale(), th.clock)
+	}
 
-		_, err := schema.Column(columnPath)
+	for _, columnPath := range request.Columns() {
+		column, err := schema.Column(columnPath)
 		if err != nil {
-			return fmt.Errorf("unknown column %s", columnPath)
+			errs.Add(datasource.ValidationUnknownColumn, datasource.CodeUnknownColumn, columnPath, "unknown column %s", columnPath)
+			continue
 		}
 
-		pathResolver := th.resolvers[column.PathResolver]
-		if pathResolver == nil {
-			return fmt.Errorf("unknown path resolver %s on column %s", column.PathResolver, columnPath)
+		if th.resolvers[column.PathResolver] == nil {
+			errs.Add(datasource.ValidationUnknownResolver, datasource.CodeUnknownPathResolver, columnPath,
+				"unknown path resolver %s on column %s", column.PathResolver, columnPath)
 		}
 
-		columnFilter := th.filters[column.Filter]
-		if columnFilter == nil {
-			return fmt.Errorf("unknown filter %s on column %s", column.Filter, columnPath)
+		if th.filters[column.Filter] == nil {
+			errs.Add(datasource.ValidationUnknownFilter, datasource.CodeUnknownFilter, columnPath,
+				"unknown filter %s on column %s", column.Filter, columnPath)
 		}
 
-		columnOrder := th.sorters[column.Order]
-		if columnOrder == nil {
-			return fmt.Errorf("unknown order %s on column %s", column.Order, columnPath)
+		if th.sorters[column.Order] == nil {
+			errs.Add(datasource.ValidationUnknownOrder, datasource.CodeUnknownOrder, columnPath,
+				"unknown order %s on column %s", column.Order, columnPath)
 		}
 	}
 
-	for _, filterGroup := range filters {
-		columnPath := filterGroup.Path()
+	th.validateFilters(&errs, request.FilterExpression(), schema, environment)
+
+	for _, column := range request.Orders() {
+		columnPath := column.Path()
 
 		if _, err := schema.Column(columnPath); err == config.ErrUnknownColumn {
-			return fmt.Errorf("unknown filter column %s", columnPath)
+			errs.Add(datasource.ValidationUnknownColumn, datasource.CodeUnknownOrderColumn, columnPath,
+				"unknown order column %s", columnPath)
 		}
 	}
 
-	for _, column := range orders {
-		columnPath := column.Path()
+	return errs.Err()
+}
 
-		if _, err := schema.Column(columnPath); err == config.ErrUnknownColumn {
-			return fmt.Errorf("unknown order column %s", columnPath)
+// Validates the columns, modes and values of all filters of the expression.
+func (th Connector) validateFilters(errs *datasource.ValidationErrors, filterExpression datasource.FilterExpression,
+	schema config.ResolvedTableSchema, environment dates.Environment) {
+	for _, filterGroup := range datasource.FilterGroupsOf(filterExpression) {
+		columnPath := filterGroup.Path()
+
+		column, err := schema.Column(columnPath)
+		if err != nil {
+			errs.Add(datasource.ValidationUnknownColumn, datasource.CodeUnknownFilterColumn, columnPath,
+				"unknown filter column %s", columnPath)
+			continue
 		}
+
+		columnFilter := th.columnFilter(column, environment)
+		if columnFilter == nil {
+			errs.Add(datasource.ValidationUnknownFilter, datasource.CodeUnknownFilter, columnPath,
+				"unknown filter %s on column %s", column.Filter, columnPath)
+			continue
+		}
+
+		for _, groupFilter := range filterGroup.Filters() {
+			if _, err := columnFilter.Operator(groupFilter.Value(), groupFilter.FilterMode()); err != nil {
+				errs.Add(datasource.ValidationUnsupportedFilterMode, datasource.CodeUnsupportedFilterMode, columnPath,
+					"unsupported filter mode %s on column %s, supported modes are %s", groupFilter.FilterMode(), columnPath,
+					joinFilterModes(filter.SupportedModes(columnFilter)))
+			}
+
+			values, err := filterValues(groupFilter)
+			if err != nil {
+				errs.Add(datasource.ValidationInvalidValue, datasource.CodeInvalidFilterValue, columnPath,
+					"invalid value %v on column %s: %s", groupFilter.Value(), columnPath, err)
+				continue
+			}
+
+			for _, value := range values {
+				if _, err := columnFilter.ParseValue(value); err != nil {
+					errs.Add(datasource.ValidationInvalidValue, datasource.CodeInvalidFilterValue, columnPath,
+						"invalid value %v on column %s: %s", value, columnPath, err)
+				}
+			}
+		}
+	}
+}
+
+func joinFilterModes(filterModes []tableaux.FilterMode) string {
+	names := make([]string, len(filterModes))
+	for i, filterMode := range filterModes {
+		names[i] = string(filterMode)
 	}
 
-	return nil
+	return strings.Join(names, ", ")
 }
 
-func (th Connector) FetchData(columns []config.TableSchemaColumn, schema config.ResolvedTableSchema,
-	filters []datasource.FilterGroup, orders []datasource.Order, globalSearch string,
-	limit, offset uint64, locale string) (*datasource.Result, uint64, uint64, error) {
+func (th Connector) FetchData(request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	return th.FetchDataContext(context.Background(), request)
+}
+
+// The result of a single data fetch, which may be shared by concurrent callers.
+type fetchedData struct {
+	result        datasource.Result
+	totalCount    uint64
+	filteredCount uint64
+	continuation  string
+}
+
+// FetchDataContext fetches the data of the request. Identical requests, which are in flight at
+// the same time, share a single execution of the data query and the counts. Each caller gets
+// its own copy of the result.
+func (th Connector) FetchDataContext(ctx context.Context, request datasource.Request) (*datasource.Result, uint64, uint64, string, error) {
+	value, shared, err := th.flights.do(ctx, request.Key(), func(ctx context.Context) (interface{}, error) {
+		return th.fetchResult(ctx, request)
+	})
+	if err != nil {
+		return nil, 0, 0, "", err
+	}
+
+	if shared {
+		log.WithField("schema", request.Schema()).Debug("Shared data of identical in-flight request")
+	}
+
+	fetched := value.(fetchedData)
+	dataResult := fetched.result.Copy()
+
+	return &dataResult, fetched.totalCount, fetched.filteredCount, fetched.continuation, nil
+}
+
+func (th Connector) fetchResult(ctx context.Context, request datasource.Request) (fetchedData, error) {
 	start := time.Now()
 
+	rows, err := th.FetchRows(ctx, request)
+	if err != nil {
+		return fetchedData{}, err
+	}
+
+	defer util.LoggingRowsCloser(rows, "datafetch")
+
+	dataResult := datasource.Result{}
+	for rows.Next() {
+		dataResult = append(dataResult, rows.Row())
+	}
+
+	if err := rows.Err(); err != nil {
+		return fetchedData{}, err
+	}
+
+	continuation, err := rows.Continuation()
+	if err != nil {
+		return fetchedData{}, err
+	}
+
+	totalCount, filteredCount, err := rows.Counts()
+	if err != nil {
+		return fetchedData{}, err
+	}
+
+	log.WithFields(
+		"time", time.Since(start),
+		"totalCount", totalCount,
+		"filteredCount", filteredCount,
+		"count", len(dataResult),
+	).Info("Data fetched")
+
+	return fetchedData{
+		result:        dataResult,
+		totalCount:    totalCount,
+		filteredCount: filteredCount,
+		continuation:  continuation,
+	}, nil
+}
+
+func (th Connector) FetchRows(ctx context.Context, request datasource.Request) (datasource.Rows, error) {
+	// Cancel everything that is still running (e.g. the counts) once the rows are closed,
+	// or immediately, if we fail to open them
+	ctx, cancel := context.WithCancel(ctx)
+
+	rows, err := th.openRows(ctx, request)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	rows.cancel = cancel
+	return rows, nil
+}
+
+// The prepared execution of a request, before any query is run.
+type requestPlan struct {
+	schema           config.ResolvedTableSchema
+	columns          []config.TableSchemaColumn
+	filterExpression datasource.FilterExpression
+	search           globalSearch
+	orders           []datasource.Order
+	keys             *keyset
+	limit            uint64
+	offset           uint64
+	locale           string
+	environment      dates.Environment
+
+	// Whether the primary keys are fetched first, and the data is fetched for these keys
+	deferredLoading bool
+	primaryKeyPath  string
+}
+
+// Prepares the execution of a request, that is resolving the columns, deciding on deferred
+// loading, and resolving the keyset pagination.
+func (th Connector) planRequest(request datasource.Request) (requestPlan, error) {
+	schema, columns, err := th.resolveRequest(request)
+	if err != nil {
+		return requestPlan{}, err
+	}
+
+	environment, err := th.environment(request.TimeZone(), request.Locale())
+	if err != nil {
+		return requestPlan{}, err
+	}
+
+	plan := requestPlan{
+		schema:           schema,
+		columns:          columns,
+		filterExpression: request.FilterExpression(),
+		orders:           request.Orders(),
+		limit:            request.Limit(),
+		offset:           request.Offset(),
+		locale:           request.Locale(),
+		environment:      environment,
+	}
+
+	if request.GlobalSearch() != "" {
+		plan.search = globalSearch{term: request.GlobalSearch(), columns: columns, locale: plan.locale}
+	}
+
 	entity := schema.OriginalSchema().Entity
 
-	// Kick-off the result counting - we need that at the end, so it can run in parallel
-	totalCountChannel := make(chan uint64, 1)
-	go th.countQuery(schema, totalCountChannel, nil)
-	//defer close(totalCountChannel)
+	plan.deferredLoading = adviseDeferredLoading(columns, plan.orders, schema)
+
+	// Keyset pagination relies on the stable primary key order, so add it right away
+	plan.orders = th.stableOrders(plan.orders, entity)
+
+	if plan.limit > 0 {
+		plan.keys, err = th.resolveKeyset(plan.orders, schema, plan.locale)
+		if err != nil {
+			return requestPlan{}, err
+		}
+
+		if request.Continuation() != "" {
+			if plan.keys == nil {
+				return requestPlan{}, ErrKeysetUnsupported
+			}
+
+			plan.keys.after, err = decodeContinuation(request.Continuation(), plan.orders)
+			if err != nil {
+				return requestPlan{}, err
+			}
+
+			// The continuation replaces the offset
+			plan.offset = 0
+		}
+	}
+
+	if plan.deferredLoading {
+		// For deferred loading, we only care about selecting the primary key
+		plan.primaryKeyPath = entity + "_" + util.IdentifierToDescriptor(th.dbConnector.KeyResolver().ResolvePrimaryKey(entity)[0])
+	}
+
+	return plan, nil
+}
+
+func (th Connector) openRows(ctx context.Context, request datasource.Request) (*Rows, error) {
+	plan, err := th.planRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resultRows := &Rows{
+		ctx:         ctx,
+		dbConnector: th.dbConnector,
+	}
+
+	// Kick-off the result counting - we need that at the end, so it can run in parallel. The
+	// channels are buffered, so the count goroutines never block, even if nobody waits for them.
+	resultRows.totalCountChannel = make(chan countResult, 1)
+	go th.countQuery(ctx, QueryTotalCount, plan.schema, resultRows.totalCountChannel, nil, globalSearch{}, plan.environment)
 
 	// Only count filtered results if we actually have filters
-	var filterCountChannel chan uint64
-	if len(filters) > 0 {
-		filterCountChannel = make(chan uint64, 1)
-		go th.countQuery(schema, filterCountChannel, filters)
-		//defer close(filterCountChannel)
+	if plan.filterExpression != nil || plan.search.term != "" {
+		resultRows.filterCountChannel = make(chan countResult, 1)
+		go th.countQuery(ctx, QueryFilteredCount, plan.schema, resultRows.filterCountChannel, plan.filterExpression, plan.search, plan.environment)
 	}
 
 	// --------
 
-	useDeferredLoading := adviseDeferredLoading(columns, orders, schema)
-	if useDeferredLoading {
-		// For deferred loading, we only care about selecting the primary key
-		primaryKeyPath := entity + "_" + util.IdentifierToDescriptor(th.dbConnector.KeyResolver().ResolvePrimaryKey(entity)[0])
-
-		// --------
+	columns := plan.columns
+	filterExpression := plan.filterExpression
+	search := plan.search
+	orders := plan.orders
+	keys := plan.keys
+	limit := plan.limit
+	offset := plan.offset
 
+	if plan.deferredLoading {
 		// Fetch the primary keys
-		rows, err := th.fetchData([]config.TableSchemaColumn{
-			{Path: primaryKeyPath},
-		}, filters, orders, schema, limit, offset, locale)
+		primaryKeys, keysetValues, err := th.fetchPrimaryKeys(ctx, plan)
 		if err != nil {
-			return nil, 0, 0, err
+			return nil, err
 		}
 
-		defer util.LoggingRowsCloser(rows, "deferredLoading-PK-fetch")
-
-		var primaryKey string
-		var primaryKeys []interface{}
-
-		for rows.Next() {
-			err := rows.Scan(&primaryKey)
+		// A full page might be followed by another one
+		if keys != nil && uint64(len(primaryKeys)) == limit {
+			resultRows.continuation, err = encodeContinuation(keys.orders, keysetValues)
 			if err != nil {
-				return nil, 0, 0, err
+				return nil, err
 			}
-
-			primaryKeys = append(primaryKeys, primaryKey)
 		}
 
 		// No keys? Then short-circuit to the empty response
 		if len(primaryKeys) == 0 {
-			totalCount := waitAndCloseChannel(totalCountChannel)
-			return &datasource.Result{}, 0, totalCount, nil
+			return resultRows, nil
 		}
 
 		// Apply the primary keys as the new order of the actual data fetch
 		orders = []datasource.Order{
 			datasource.NewOrder(
-				primaryKeyPath,
+				plan.primaryKeyPath,
 				tableaux.OrderAsc,
 				primaryKeys,
 			),
 		}
 
-		// Replace existing filters with primary key filter
-		filters = []datasource.FilterGroup{
-			datasource.NewSimpleFilterGroup(
-				primaryKeyPath,
-				tableaux.FilterEquals,
-				primaryKeys,
-			),
-		}
+		// Replace existing filters and the search with primary key filter
+		filterExpression = datasource.NewSimpleFilterGroup(
+			plan.primaryKeyPath,
+			tableaux.FilterEquals,
+			primaryKeys,
+		)
+		search = globalSearch{}
 
 		// Ensure that the data fetch does neither offset nor limit
 		limit = 0
 		offset = 0
+		keys = nil
 	}
 
-	rows, err := th.fetchData(columns, filters, orders, schema, limit, offset, locale)
+	rows, observation, err := th.fetchData(ctx, QueryData, columns, filterExpression, search, plan.environment, orders, keys, plan.schema, limit, offset, plan.locale)
 	if err != nil {
-		return nil, 0, 0, err
+		return nil, err
 	}
 
-	defer util.LoggingRowsCloser(rows, "datafetch")
+	resultRows.rows = rows
+	resultRows.observation = observation
+	resultRows.keyset = keys
+	resultRows.limit = limit
+	if err := resultRows.init(); err != nil {
+		observation.finish(0, err)
+		util.LoggingRowsCloser(rows, "datafetch")
+		return nil, err
+	}
 
-	cols, err := rows.Columns()
+	return resultRows, nil
+}
+
+// Fetches the primary keys of all rows matching the request, for deferred loading. If keyset
+// pagination is used, the sort values of the last row are returned as well.
+func (th Connector) fetchPrimaryKeys(ctx context.Context, plan requestPlan) (primaryKeys []interface{}, keysetValues []interface{}, err error) {
+	rows, observation, err := th.fetchData(ctx, QueryPrimaryKeys, []config.TableSchemaColumn{
+		{Path: plan.primaryKeyPath},
+	}, plan.filterExpression, plan.search, plan.environment, plan.orders, plan.keys, plan.schema, plan.limit, plan.offset, plan.locale)
 	if err != nil {
-		log.Fatal("Failed to get selectColumns", err)
+		return nil, nil, err
 	}
 
-	// Result is your slice string.
-	result := make([][]byte, len(cols))
-	dest := make([]interface{}, len(cols))
+	defer util.LoggingRowsCloser(rows, "deferredLoading-PK-fetch")
+	defer func() {
+		observation.finish(uint64(len(primaryKeys)), err)
+	}()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// A temporary interface{} slice
-	for i := range result {
-		dest[i] = &result[i] // Put pointers to each string in the interface slice
+	raw := make([][]byte, len(types))
+	dest := make([]interface{}, len(types))
+	for i := range raw {
+		dest[i] = &raw[i]
 	}
 
-	types, _ := rows.ColumnTypes()
+	keysetValues = make([]interface{}, len(types)-1)
 
-	dataResult := datasource.Result{}
 	for rows.Next() {
 		err := rows.Scan(dest...)
 		if err != nil {
-			log.Fatal(err)
+			return nil, nil, err
 		}
 
-		row := make(map[string]interface{}, len(result))
-		for i := 0; i < len(result); i++ {
-			name := strings.Replace(types[i].Name(), ".", "_", -1)
+		primaryKeys = append(primaryKeys, string(raw[0]))
 
-			value, err := th.dbConnector.MakeItemTypeSafe(result[i], types[i])
+		for i := 1; i < len(raw); i++ {
+			keysetValues[i-1], err = th.dbConnector.MakeItemTypeSafe(raw[i], types[i])
 			if err != nil {
-				return nil, 0, 0, err
+				return nil, nil, err
 			}
+		}
+	}
+
+	return primaryKeys, keysetValues, rows.Err()
+}
+
+// Resolves the schema and the selected columns of a request.
+func (th Connector) resolveRequest(request datasource.Request) (config.ResolvedTableSchema, []config.TableSchemaColumn, error) {
+	schema, err := th.schemaMapper.ResolvedSchema(request.Schema())
+	if err != nil {
+		return config.ResolvedTableSchema{}, nil, err
+	}
 
-			row[name] = value
+	columns := make([]config.TableSchemaColumn, len(request.Columns()))
+	for i, columnPath := range request.Columns() {
+		columns[i], err = schema.Column(columnPath)
+		if err != nil {
+			return config.ResolvedTableSchema{}, nil, err
 		}
+	}
 
-		dataResult = append(dataResult, row)
+	return schema, columns, nil
+}
+
+// Loads the location of a time zone by its name. An empty name stands for UTC.
+func loadLocation(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return time.UTC, nil
 	}
 
-	totalCount := waitAndCloseChannel(totalCountChannel)
+	return time.LoadLocation(timeZone)
+}
 
-	filteredCount := totalCount
-	if filterCountChannel != nil {
-		filteredCount = waitAndCloseChannel(filterCountChannel)
+// Returns the filter of a column, which is bound to the column and to the environment of the
+// request, if it is a filter.ColumnFilter. Returns nil, if the filter is unknown.
+func (th Connector) columnFilter(column config.TableSchemaColumn, environment dates.Environment) filter.Filter {
+	columnFilter := th.filters[column.Filter]
+	if bindable, isColumnFilter := columnFilter.(filter.ColumnFilter); isColumnFilter {
+		return bindable.ForColumn(column, environment)
 	}
 
-	log.WithFields(
-		"time", time.Since(start),
-		"totalCount", totalCount,
-		"filteredCount", filteredCount,
-		"count", len(dataResult),
-	).Info("Data fetched")
+	return columnFilter
+}
+
+// Creates the environment, which the filter values of a request are interpreted in.
+func (th Connector) environment(timeZone, locale string) (dates.Environment, error) {
+	location, err := loadLocation(timeZone)
+	if err != nil {
+		return dates.Environment{}, err
+	}
+
+	return dates.NewEnvironment(location, locale, th.clock), nil
+}
+
+// Waits for a count to arrive on the given channel, or returns the context error as its
+// outcome, if the context is done first.
+func waitForCount(ctx context.Context, channel chan countResult) countResult {
+	select {
+	case result := <-channel:
+		return result
+	case <-ctx.Done():
+		return countResult{err: ctx.Err()}
+	}
+}
 
-	return &dataResult, totalCount, filteredCount, nil
+// globalSearch bundles the global search term with the columns it is applied to.
+// The zero value describes no search at all.
+type globalSearch struct {
+	term    string
+	columns []config.TableSchemaColumn
+	locale  string
 }
 
-func waitAndCloseChannel(channel chan uint64) uint64 {
-	count := <-channel
-	close(channel)
-	return count
+// Merges multiple column slices into a new slice, without altering the given slices.
+func mergeColumns(columnSlices ...[]config.TableSchemaColumn) []config.TableSchemaColumn {
+	var merged []config.TableSchemaColumn
+	for _, columns := range columnSlices {
+		merged = append(merged, columns...)
+	}
+
+	return merged
 }
 
 // Calculates all paths that are participating in the request, be it trough selection, filtering or ordering.
 func mergedParticipatingPaths(columns []config.TableSchemaColumn, orders []datasource.Order,
-	filters []datasource.FilterGroup) map[string]interface{} {
+	filterExpression datasource.FilterExpression) map[string]interface{} {
 	pathMap := make(map[string]interface{})
 
 	for _, column := range columns {
@@ -277,8 +610,10 @@ func mergedParticipatingPaths(columns []config.TableSchemaColumn, orders []datas
 		pathMap[columnOrder.Path()] = struct{}{}
 	}
 
-	for _, columnFilter := range filters {
-		pathMap[columnFilter.Path()] = struct{}{}
+	if filterExpression != nil {
+		for _, filterPath := range filterExpression.Paths() {
+			pathMap[filterPath] = struct{}{}
+		}
 	}
 
 	return pathMap
@@ -287,8 +622,8 @@ func mergedParticipatingPaths(columns []config.TableSchemaColumn, orders []datas
 // Calculates all the paths that require joins, for a given request. This method looks at the selected columns,
 // ordering and filtering to determinate what needs to be joined.
 func calculatePathsForJoins(columns []config.TableSchemaColumn, orders []datasource.Order,
-	filters []datasource.FilterGroup) []string {
-	participatingPaths := mergedParticipatingPaths(columns, orders, filters)
+	filterExpression datasource.FilterExpression) []string {
+	participatingPaths := mergedParticipatingPaths(columns, orders, filterExpression)
 	if len(participatingPaths) == 0 {
 		return []string{}
 	}
@@ -320,8 +655,8 @@ func calculatePathsForJoins(columns []config.TableSchemaColumn, orders []datasou
 }
 
 func calculatePathsForCountJoins(columns []config.TableSchemaColumn, orders []datasource.Order,
-	filters []datasource.FilterGroup, schema config.ResolvedTableSchema) []string {
-	participatingPaths := mergedParticipatingPaths(columns, orders, filters)
+	filterExpression datasource.FilterExpression, schema config.ResolvedTableSchema) []string {
+	participatingPaths := mergedParticipatingPaths(columns, orders, filterExpression)
 	if len(participatingPaths) == 0 {
 		return []string{}
 	}
@@ -369,21 +704,61 @@ func adviseDeferredLoading(columns []config.TableSchemaColumn, orders []datasour
 	return false
 }
 
-func (th Connector) fetchData(columns []config.TableSchemaColumn, filters []datasource.FilterGroup, orders []datasource.Order,
-	schema config.ResolvedTableSchema, limit, offset uint64, locale string) (*sql.Rows, error) {
-	var err error
+// Executes a data query. The returned observation must be finished, once the rows were read.
+func (th Connector) fetchData(ctx context.Context, kind QueryKind, columns []config.TableSchemaColumn, filterExpression datasource.FilterExpression,
+	search globalSearch, environment dates.Environment, orders []datasource.Order, keys *keyset,
+	schema config.ResolvedTableSchema, limit, offset uint64, locale string) (*sql.Rows, *queryObservation, error) {
+	queryString, arguments, err := th.dataQuery(columns, filterExpression, search, environment, orders, keys, schema, limit, offset, locale)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	observation := th.observeQuery(ctx, kind, schema.OriginalSchema().Entity, queryString, arguments.Values())
 
+	statement, err := th.dbConnector.DatabaseObject().PrepareContext(ctx, queryString)
+	if err != nil {
+		log.WithField("query", queryString).Error("Failed to prepare query")
+		observation.finish(0, err)
+		return nil, nil, err
+	}
+
+	// The statement is only released once the rows returned from it are closed as well
+	defer statement.Close()
+
+	log.WithFields(
+		"query", queryString,
+		"arguments", arguments.Values(),
+	).Debug("Executing query")
+
+	start := time.Now()
+	rows, rowsErr := statement.QueryContext(ctx, arguments.Values()...)
+
+	log.WithFields(
+		"time", time.Since(start),
+		"columns", len(columns),
+	).Debug("Query successfully executed for data source")
+
+	if rowsErr != nil {
+		observation.finish(0, rowsErr)
+		return nil, nil, rowsErr
+	}
+
+	return rows, observation, nil
+}
+
+// Constructs the data query, and the arguments bound to it.
+func (th Connector) dataQuery(columns []config.TableSchemaColumn, filterExpression datasource.FilterExpression,
+	search globalSearch, environment dates.Environment, orders []datasource.Order, keys *keyset,
+	schema config.ResolvedTableSchema, limit, offset uint64, locale string) (string, *Arguments, error) {
 	queryBuilder := th.dbConnector.QueryBuilder()
-	keyResolver := th.dbConnector.KeyResolver()
 
-	db := th.dbConnector.DatabaseObject()
 	entity := schema.OriginalSchema().Entity
 
 	// ---------------------------
 
-	joinString, err := th.resolveJoinString(columns, orders, schema, filters)
+	joinString, err := th.resolveJoinString(mergeColumns(columns, search.columns), orders, schema, filterExpression)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	// ---------------------------
@@ -394,136 +769,242 @@ func (th Connector) fetchData(columns []config.TableSchemaColumn, filters []data
 		selectColumns[i] = resolver.ResolvePathName(column) + " AS " + column.Path
 	}
 
+	// The sort values are needed to continue after the last row
+	if keys != nil {
+		selectColumns = append(selectColumns, keys.selectColumns()...)
+	}
+
 	// ---------------------------
 
-	pkPath := entity + "_" + keyResolver.ResolvePrimaryKey(entity)[0]
-	containsPKOrder := false
-	for _, value := range orders {
-		if value.Path() == pkPath {
-			containsPKOrder = true
-			break
+	orders = th.stableOrders(orders, entity)
+
+	// ---------------------------
+
+	// The query parts are assembled in order of appearance, so the bound arguments line up
+	arguments := NewArguments(queryBuilder)
+
+	queryString := strings.Join(selectColumns, ",") + " FROM " + entity
+	if joinString != "" {
+		queryString += " " + joinString
+	}
+
+	whereString, err := th.whereString(filterExpression, search, environment, schema, arguments)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if keys != nil && keys.after != nil {
+		if whereString != "" {
+			whereString = "(" + whereString + ") AND " + keys.predicate(arguments)
+		} else {
+			whereString = keys.predicate(arguments)
 		}
 	}
 
-	// Guarantee primary key sort, so we get stable results
-	if !containsPKOrder {
-		log.Info("Request does not contain order on primary key - adding order to ensure consistent results")
-		orders = append(orders, datasource.NewOrder(pkPath, tableaux.OrderAsc, nil))
+	if whereString != "" {
+		queryString += " WHERE " + whereString
 	}
 
 	sortColumns := make([]string, len(orders))
-	builder := th.dbConnector.QueryBuilder()
 	for i, value := range orders {
-		resolver := th.resolvers[""]
+		column, resolvedPath := th.resolveOrderColumn(value, schema)
 
-		column, colErr := schema.Column(value.Path())
-		if colErr == nil {
-			resolver = th.resolvers[column.PathResolver]
-		} else {
-			column = config.TableSchemaColumn{
-				Path: value.Path(),
-			}
-			log.WithFields(
-				"path", value.Path(),
-				"schema", schema.OriginalSchema().Entity,
-			).Warn("Ordering on column which is unknown to schema - using default path resolver")
+		sortColumns[i], err = OrderColumn(queryBuilder, resolvedPath, column, th.sorters[column.Order], value, locale, arguments)
+		if err != nil {
+			return "", nil, err
 		}
+	}
 
-		resolvedPath := resolver.ResolvePathName(column)
+	queryString += " ORDER BY " + strings.Join(sortColumns, ",")
 
-		sortColumns[i] = OrderColumn(builder, resolvedPath, column, th.sorters[column.Order], value, locale)
-	}
+	if limit > 0 {
+		limitPlaceholder := arguments.Bind(limit)
 
-	// ---------------------------
+		offsetPlaceholder := ""
+		if offset > 0 {
+			offsetPlaceholder = arguments.Bind(offset)
+		}
 
-	queryString := strings.Join(selectColumns, ",") + " FROM " + entity
-	if joinString != "" {
-		queryString += " " + joinString
-	}
+		queryString = queryBuilder.SelectWithLimitQuery(queryString, limitPlaceholder, offsetPlaceholder)
+	} else {
+		if offset > 0 {
+			log.WithField("offset", offset).Warn("Ignoring offset for request without limit")
+		}
 
-	filterString, err := th.filterString(filters, schema)
-	if err != nil {
-		return nil, err
+		queryString = "SELECT " + queryString
 	}
 
-	if filterString != "" {
-		queryString += " WHERE " + filterString
+	return queryString, arguments, nil
+}
+
+// Appends the primary key order, if not already present, which guarantees stable results.
+func (th Connector) stableOrders(orders []datasource.Order, entity string) []datasource.Order {
+	pkPath := entity + "_" + th.dbConnector.KeyResolver().ResolvePrimaryKey(entity)[0]
+	for _, value := range orders {
+		if value.Path() == pkPath {
+			return orders
+		}
 	}
 
-	queryString += " ORDER BY " + strings.Join(sortColumns, ",")
+	log.Info("Request does not contain order on primary key - adding order to ensure consistent results")
 
-	if limit > 0 {
-		queryString = queryBuilder.SelectWithLimitQuery(queryString)
+	stableOrders := make([]datasource.Order, len(orders), len(orders)+1)
+	copy(stableOrders, orders)
+
+	return append(stableOrders, datasource.NewOrder(pkPath, tableaux.OrderAsc, nil))
+}
+
+// Resolves the schema column of an order, and the resolved path to order by. Orders on columns
+// unknown to the schema are resolved via the default path resolver.
+func (th Connector) resolveOrderColumn(value datasource.Order, schema config.ResolvedTableSchema) (config.TableSchemaColumn, string) {
+	resolver := th.resolvers[""]
+
+	column, colErr := schema.Column(value.Path())
+	if colErr == nil {
+		resolver = th.resolvers[column.PathResolver]
 	} else {
-		queryString = "SELECT " + queryString
+		column = config.TableSchemaColumn{
+			Path: value.Path(),
+		}
+		log.WithFields(
+			"path", value.Path(),
+			"schema", schema.OriginalSchema().Entity,
+		).Warn("Ordering on column which is unknown to schema - using default path resolver")
 	}
 
-	statement, err := db.Prepare(queryString)
+	return column, resolver.ResolvePathName(column)
+}
+
+// Combines the filters and the global search into a single condition.
+func (th Connector) whereString(filterExpression datasource.FilterExpression, search globalSearch, environment dates.Environment,
+	schema config.ResolvedTableSchema, arguments *Arguments) (string, error) {
+	filterString, err := th.filterString(filterExpression, environment, schema, arguments)
 	if err != nil {
-		log.WithField("query", queryString).Error("Failed to prepare query")
-		return nil, err
+		return "", err
 	}
 
-	log.WithField("query", queryString).Debug("Executing query")
+	searchString, err := th.searchString(search, environment, arguments)
+	if err != nil {
+		return "", err
+	}
 
-	start := time.Now()
-	var (
-		rows    *sql.Rows
-		rowsErr error
-	)
-	if limit > 0 {
-		rows, rowsErr = statement.Query(limit)
-	} else {
-		rows, rowsErr = statement.Query()
+	if filterString == "" {
+		return searchString, nil
 	}
 
-	log.WithFields(
-		"time", time.Since(start),
-		"columns", len(columns),
-	).Debug("Query successfully executed for data source")
+	if searchString == "" {
+		return filterString, nil
+	}
 
-	return rows, rowsErr
+	// Filters might contain OR chains, so they must be parenthesized
+	return "(" + filterString + ") AND " + searchString, nil
 }
 
-func (th Connector) filterString(filters []datasource.FilterGroup, schema config.ResolvedTableSchema) (string, error) {
+// Constructs the condition for the global search, which OR's a match of the search term over all
+// searchable columns. How the term is matched is decided by the filter of each column. If no column
+// can match the term, the condition matches nothing.
+func (th Connector) searchString(search globalSearch, environment dates.Environment, arguments *Arguments) (string, error) {
+	if search.term == "" {
+		return "", nil
+	}
+
 	queryBuilder := th.dbConnector.QueryBuilder()
 
-	uniqueFilterPaths := make(map[string][]datasource.FilterGroup)
-	for _, filterGroup := range filters {
-		uniqueFilterPaths[filterGroup.Path()] = append(uniqueFilterPaths[filterGroup.Path()], filterGroup)
+	var orSearchStrings []string
+	for _, column := range search.columns {
+		columnFilter := th.columnFilter(column, environment)
+
+		searcher, canSearch := columnFilter.(filter.Searcher)
+		if !canSearch {
+			continue
+		}
+
+		operator, values := searcher.Search(search.term, column, search.locale)
+		if len(values) == 0 {
+			continue
+		}
+
+		resolvedPath := th.resolvers[column.PathResolver].ResolvePathName(column)
+
+		columnSearchString, err := queryBuilder.FilterStringFromValues(resolvedPath, columnFilter, operator, values, arguments)
+		if err != nil {
+			return "", err
+		}
+
+		orSearchStrings = append(orSearchStrings, columnSearchString)
 	}
 
-	andFilterStrings := make([]string, len(uniqueFilterPaths))
-	i := 0
-	for rawPath, filterGroups := range uniqueFilterPaths {
-		schemaColumn, err := schema.Column(rawPath)
+	if len(orSearchStrings) == 0 {
+		return "1 = 0", nil
+	}
+
+	return "(" + strings.Join(orSearchStrings, " OR ") + ")", nil
+}
+
+// Renders a FilterExpression tree into a condition. Every combining node is parenthesized,
+// so the precedence of the tree is retained.
+func (th Connector) filterString(filterExpression datasource.FilterExpression, environment dates.Environment,
+	schema config.ResolvedTableSchema, arguments *Arguments) (string, error) {
+	switch node := filterExpression.(type) {
+	case nil:
+		return "", nil
+	case datasource.FilterGroup:
+		schemaColumn, err := schema.Column(node.Path())
 		if err != nil {
 			return "", err
 		}
 
-		columnFilter := th.filters[schemaColumn.Filter]
+		columnFilter := th.columnFilter(schemaColumn, environment)
 		resolver := th.resolvers[schemaColumn.PathResolver]
 		resolvedPath := resolver.ResolvePathName(schemaColumn)
 
-		columnFilterString, err := FilterColumn(queryBuilder, resolvedPath, columnFilter, filterGroups)
+		return FilterColumn(th.dbConnector.QueryBuilder(), resolvedPath, columnFilter, []datasource.FilterGroup{node}, environment, arguments)
+	case datasource.AndExpression:
+		return th.joinFilterStrings(node.Expressions(), " AND ", "1 = 1", environment, schema, arguments)
+	case datasource.OrExpression:
+		return th.joinFilterStrings(node.Expressions(), " OR ", "1 = 0", environment, schema, arguments)
+	case datasource.NotExpression:
+		filterString, err := th.filterString(node.Expression(), environment, schema, arguments)
+		if err != nil || filterString == "" {
+			return filterString, err
+		}
+
+		return "NOT (" + filterString + ")", nil
+	default:
+		return "", fmt.Errorf("unknown filter expression %T", filterExpression)
+	}
+}
+
+// Renders multiple expressions, and joins them with the given operator. If there are no
+// expressions to join, the neutral condition is returned instead.
+func (th Connector) joinFilterStrings(expressions []datasource.FilterExpression, operator, neutral string,
+	environment dates.Environment, schema config.ResolvedTableSchema, arguments *Arguments) (string, error) {
+	var filterStrings []string
+	for _, expression := range expressions {
+		filterString, err := th.filterString(expression, environment, schema, arguments)
 		if err != nil {
 			return "", err
 		}
 
-		andFilterStrings[i] = columnFilterString
-		i++
+		if filterString != "" {
+			filterStrings = append(filterStrings, filterString)
+		}
 	}
 
-	return strings.Join(andFilterStrings, " AND "), nil
+	if len(filterStrings) == 0 {
+		return neutral, nil
+	}
+
+	return "(" + strings.Join(filterStrings, operator) + ")", nil
 }
 
-func (th Connector) resolveJoinString(columns []config.TableSchemaColumn, orders []datasource.Order, schema config.ResolvedTableSchema, filters []datasource.FilterGroup) (string, error) {
+func (th Connector) resolveJoinString(columns []config.TableSchemaColumn, orders []datasource.Order, schema config.ResolvedTableSchema, filterExpression datasource.FilterExpression) (string, error) {
 	queryBuilder := th.dbConnector.QueryBuilder()
 	joinResolver := th.dbConnector.JoinResolver()
 	keyResolver := th.dbConnector.KeyResolver()
 
 	// Sort the joins, and then convert them to database specific joins
-	joinStrings := calculatePathsForJoins(columns, orders, filters)
+	joinStrings := calculatePathsForJoins(columns, orders, filterExpression)
 	for index, columnPath := range joinStrings {
 		resolvedPath, err := joinResolver.ResolvePath(columnPath)
 		if err != nil {
@@ -536,7 +1017,7 @@ func (th Connector) resolveJoinString(columns []config.TableSchemaColumn, orders
 	// ---------------------------
 
 	// Finished resolving the primary joins. Now we resolve the count joins.
-	for _, columnPath := range calculatePathsForCountJoins(columns, orders, filters, schema) {
+	for _, columnPath := range calculatePathsForCountJoins(columns, orders, filterExpression, schema) {
 		countJoin, err := joinResolver.ResolveCountJoin(columnPath, th.schemaMapper, keyResolver)
 		if err != nil {
 			log.WithField("path", columnPath).Error("Cannot resolve count join")
@@ -549,35 +1030,59 @@ func (th Connector) resolveJoinString(columns []config.TableSchemaColumn, orders
 	return strings.Join(joinStrings, " "), nil
 }
 
-func (th Connector) countQuery(schema config.ResolvedTableSchema, countChannel chan uint64, filters []datasource.FilterGroup) {
+// Executes a count query, and sends its outcome to the channel. Failures are sent as *CountError.
+func (th Connector) countQuery(ctx context.Context, kind QueryKind, schema config.ResolvedTableSchema, countChannel chan countResult,
+	filterExpression datasource.FilterExpression, search globalSearch, environment dates.Environment) {
 	var count uint64
 
-	pk := th.dbConnector.KeyResolver().ResolvePrimaryKey(schema.OriginalSchema().Entity)[0]
-	joinString, err := th.resolveJoinString([]config.TableSchemaColumn{}, []datasource.Order{}, schema, filters)
+	queryString, arguments, err := th.countQueryString(schema, filterExpression, search, environment)
 	if err != nil {
-		log.Fatal(err)
+		countChannel <- countResult{err: &CountError{Kind: kind, Err: err}}
+		return
 	}
 
-	queryString := "SELECT count(" + schema.OriginalSchema().Entity + "." + pk + ") FROM " + schema.OriginalSchema().Entity
-	if joinString != "" {
-		queryString += " " + joinString
+	log.WithFields(
+		"query", queryString,
+		"arguments", arguments.Values(),
+	).Debug("Executing query")
+
+	observation := th.observeQuery(ctx, kind, schema.OriginalSchema().Entity, queryString, arguments.Values())
+
+	err = th.dbConnector.DatabaseObject().QueryRowContext(ctx, queryString, arguments.Values()...).Scan(&count)
+	observation.finish(count, err)
+	if err != nil {
+		log.WithField("query", queryString).Error(err)
+		countChannel <- countResult{err: &CountError{Kind: kind, Err: err}}
+		return
 	}
 
-	filterString, err := th.filterString(filters, schema)
+	countChannel <- countResult{count: count}
+}
+
+// Constructs the count query, and the arguments bound to it.
+func (th Connector) countQueryString(schema config.ResolvedTableSchema, filterExpression datasource.FilterExpression,
+	search globalSearch, environment dates.Environment) (string, *Arguments, error) {
+	pk := th.dbConnector.KeyResolver().ResolvePrimaryKey(schema.OriginalSchema().Entity)[0]
+	joinString, err := th.resolveJoinString(search.columns, []datasource.Order{}, schema, filterExpression)
 	if err != nil {
-		panic(err)
+		return "", nil, err
 	}
 
-	if filterString != "" {
-		queryString += " WHERE " + filterString
+	queryString := "SELECT count(" + schema.OriginalSchema().Entity + "." + pk + ") FROM " + schema.OriginalSchema().Entity
+	if joinString != "" {
+		queryString += " " + joinString
 	}
 
-	log.WithField("query", queryString).Debug("Executing query")
+	arguments := NewArguments(th.dbConnector.QueryBuilder())
 
-	err = th.dbConnector.DatabaseObject().QueryRow(queryString).Scan(&count)
+	whereString, err := th.whereString(filterExpression, search, environment, schema, arguments)
 	if err != nil {
-		log.Error(err)
+		return "", nil, err
+	}
+
+	if whereString != "" {
+		queryString += " WHERE " + whereString
 	}
 
-	countChannel <- count
+	return queryString, arguments, nil
 }
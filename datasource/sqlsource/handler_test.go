@@ -0,0 +1,269 @@
+package sqlsource
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/filter"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/order"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/path"
+)
+
+// testDatabaseConnector is a DatabaseConnector, which returns all items as strings.
+type testDatabaseConnector struct {
+	*CommonDatabaseConnector
+}
+
+func (connector testDatabaseConnector) DatabaseVersion() (string, error) {
+	return "test", nil
+}
+
+func (connector testDatabaseConnector) MakeItemTypeSafe(item []byte, _ *sql.ColumnType) (interface{}, error) {
+	if item == nil {
+		return nil, nil
+	}
+
+	return string(item), nil
+}
+
+func TestSearchStringNumeric(t *testing.T) {
+	connector := Connector{
+		dbConnector: testDatabaseConnector{NewCommonDatabaseConnector(nil, nil, nil, numberedQueryBuilder{})},
+		resolvers: map[string]datasource.PathResolver{
+			"": path.SimpleResolver{},
+		},
+		filters: map[string]filter.Filter{
+			"NumericFilter": filter.Numeric{Common: &filter.Common{}},
+			"StringFilter":  filter.PlainString{Common: &filter.Common{}},
+		},
+	}
+
+	search := globalSearch{
+		term: "1.5",
+		columns: []config.TableSchemaColumn{
+			{Path: "product_id", Type: "long", Filter: "NumericFilter"},
+			{Path: "product_price", Type: "decimal", Filter: "NumericFilter"},
+			{Path: "product_weight", Type: "float", Filter: "NumericFilter"},
+			{Path: "product_name", Type: "string", Filter: "StringFilter"},
+		},
+	}
+
+	arguments := NewArguments(numberedQueryBuilder{})
+
+	query, err := connector.searchString(search, dates.NewEnvironment(time.UTC, "en", nil), arguments)
+	if err != nil {
+		t.Fatalf("searchString failed: %s", err)
+	}
+
+	// The term is no integral number, so the id does not take part in the search
+	want := "(product.price = $1 OR product.weight = $2 OR product.name LIKE $3 ESCAPE '!')"
+	if query != want {
+		t.Errorf("searchString was incorrect, got: %s, want: %s.", query, want)
+	}
+
+	wantArgs := []interface{}{"1.5", 1.5, "%1.5%"}
+	if !reflect.DeepEqual(arguments.Values(), wantArgs) {
+		t.Errorf("searchString bound incorrect arguments, got: %v, want: %v.", arguments.Values(), wantArgs)
+	}
+}
+
+// blockingDriver is a database driver, of which all queries block until their context is done.
+// It keeps track of the running queries, and of the statements not yet closed.
+type blockingDriver struct {
+	started    chan struct{}
+	running    int32
+	statements int32
+}
+
+func (blocking *blockingDriver) Open(_ string) (driver.Conn, error) {
+	return blockingConn{blocking}, nil
+}
+
+func (blocking *blockingDriver) Connect(_ context.Context) (driver.Conn, error) {
+	return blockingConn{blocking}, nil
+}
+
+func (blocking *blockingDriver) Driver() driver.Driver {
+	return blocking
+}
+
+type blockingConn struct {
+	driver *blockingDriver
+}
+
+func (conn blockingConn) Prepare(_ string) (driver.Stmt, error) {
+	atomic.AddInt32(&conn.driver.statements, 1)
+	return blockingStmt{conn.driver}, nil
+}
+
+func (conn blockingConn) Close() error {
+	return nil
+}
+
+func (conn blockingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type blockingStmt struct {
+	driver *blockingDriver
+}
+
+func (statement blockingStmt) Close() error {
+	atomic.AddInt32(&statement.driver.statements, -1)
+	return nil
+}
+
+func (statement blockingStmt) NumInput() int {
+	return -1
+}
+
+func (statement blockingStmt) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, errors.New("statements cannot be executed")
+}
+
+func (statement blockingStmt) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("queries require a context")
+}
+
+func (statement blockingStmt) QueryContext(ctx context.Context, _ []driver.NamedValue) (driver.Rows, error) {
+	atomic.AddInt32(&statement.driver.running, 1)
+	defer atomic.AddInt32(&statement.driver.running, -1)
+
+	statement.driver.started <- struct{}{}
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+// Creates a Connector on the blockingDriver.
+func newBlockingTestConnector(t *testing.T, blocking *blockingDriver, options ...Option) *Connector {
+	db := sql.OpenDB(blocking)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	// Keep the connections of all queries, so their statements are not closed along with them
+	db.SetMaxIdleConns(3)
+
+	connector, err := newOptionsTestConnector(t, append([]Option{
+		WithFilter("SoundexFilter", filter.PlainString{Common: &filter.Common{}}),
+		WithSorter("CollatedOrder", order.Direct{}),
+		WithPathResolver("LowerPathResolver", lowerResolver{}),
+	}, options...)...)
+	if err != nil {
+		t.Fatalf("NewConnector failed: %s", err)
+	}
+
+	keyResolver := NewCommonKeyResolver(map[string][]string{"person": {"id"}}, nil)
+	connector.dbConnector = testDatabaseConnector{NewCommonDatabaseConnector(db, nil, keyResolver, numberedQueryBuilder{})}
+
+	return connector
+}
+
+// Starts fetching the request in the background, and cancels the context once the data query
+// and both counts are running. Returns the error of FetchDataContext.
+func fetchCancelled(t *testing.T, connector *Connector, blocking *blockingDriver, ctx context.Context) error {
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(datasource.NewSimpleFilterGroup("person_id", tableaux.FilterEquals, []interface{}{"1"})).
+		Locale("en").
+		Build()
+
+	ctx, cancel := context.WithCancel(ctx)
+	fetched := make(chan error, 1)
+
+	go func() {
+		_, _, _, _, err := connector.FetchDataContext(ctx, request)
+		fetched <- err
+	}()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-blocking.started:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Only %d of 3 queries were started.", i)
+		}
+	}
+
+	cancel()
+
+	select {
+	case err := <-fetched:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("FetchDataContext did not return after cancellation.")
+		return nil
+	}
+}
+
+func TestFetchDataContextCancelled(t *testing.T) {
+	blocking := &blockingDriver{started: make(chan struct{}, 3)}
+	connector := newBlockingTestConnector(t, blocking)
+
+	goroutines := runtime.NumGoroutine()
+
+	if err := fetchCancelled(t, connector, blocking, context.Background()); err != context.Canceled {
+		t.Errorf("Error was incorrect, got: %v, want: %v.", err, context.Canceled)
+	}
+
+	// The queries are aborted in the background, after the caller stopped waiting
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&blocking.running) > 0 || atomic.LoadInt32(&blocking.statements) > 0 || runtime.NumGoroutine() > goroutines {
+		if time.Now().After(deadline) {
+			t.Fatalf("Queries were not released, got: %d running queries, %d open statements and %d goroutines, want: 0, 0 and %d.",
+				atomic.LoadInt32(&blocking.running), atomic.LoadInt32(&blocking.statements), runtime.NumGoroutine(), goroutines)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestObserverContext(t *testing.T) {
+	blocking := &blockingDriver{started: make(chan struct{}, 3)}
+
+	var mutex sync.Mutex
+	traces := make(map[QueryKind]interface{})
+
+	connector := newBlockingTestConnector(t, blocking, WithObserver(ObserverFunc(func(ctx context.Context, event QueryEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		traces[event.Kind] = ctx.Value(contextKey("trace"))
+	})))
+
+	fetchCancelled(t, connector, blocking, context.WithValue(context.Background(), contextKey("trace"), "abc"))
+
+	// The queries are only observed once they were aborted in the background
+	want := map[QueryKind]interface{}{QueryData: "abc", QueryTotalCount: "abc", QueryFilteredCount: "abc"}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mutex.Lock()
+		observed := reflect.DeepEqual(traces, want)
+		got := fmt.Sprint(traces)
+		mutex.Unlock()
+
+		if observed {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Context values of the observed queries were incorrect, got: %s, want: %v.", got, want)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -3,6 +3,7 @@ package sqlsource
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
 
 	"gopkg.in/birkirb/loggers.v1/log"
@@ -99,7 +100,7 @@ func (joinResolver *CommonJoinResolver) ResolvePath(joinPath string) (Join, erro
 		if possibleSource, exists := joinResolver.joinPathCache[joinSource]; exists {
 			joinSource = possibleSource.TargetTable()
 		} else {
-			log.Fatal("Unable to figure out table for " + joinSource)
+			return Join{}, fmt.Errorf("unable to figure out table for %s", joinSource)
 		}
 	} else {
 		joinSource = util.DescriptorToIdentifier(joinPaths[0])
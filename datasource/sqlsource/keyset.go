@@ -0,0 +1,223 @@
+package sqlsource
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+)
+
+// The version of the continuation token format.
+const continuationVersion = 1
+
+// The alias prefix of the sort values, which are additionally selected for keyset pagination.
+const keysetAliasPrefix = "keyset_"
+
+var (
+	// ErrInvalidContinuation indicates that a continuation token is malformed, or
+	// was issued for a request with different orders.
+	ErrInvalidContinuation = errors.New("invalid continuation token")
+
+	// ErrKeysetUnsupported indicates that a continuation token was supplied for a request,
+	// of which the orders cannot be used for keyset pagination (e.g. case'd orders).
+	ErrKeysetUnsupported = errors.New("orders do not support keyset pagination")
+)
+
+// keysetColumn is a single resolved sort column, which takes part in keyset pagination.
+type keysetColumn struct {
+	path      string
+	direction tableaux.Order
+
+	// Whether NULL values are ordered before all other values of the column
+	nullsFirst bool
+}
+
+// keyset describes the keyset pagination of a single query. The sort values of all columns
+// are additionally selected, so a continuation token can be built from the last row. If after
+// is set, only rows after the given sort values are fetched.
+type keyset struct {
+	orders  []datasource.Order
+	columns []keysetColumn
+	after   []interface{}
+}
+
+// Resolves the keyset for the given orders, which must already contain the primary key order
+// as a tiebreaker. If any order cannot be expressed as a plain column comparison (which is the
+// case for case'd orders), nil is returned.
+func (th Connector) resolveKeyset(orders []datasource.Order, schema config.ResolvedTableSchema, locale string) (*keyset, error) {
+	columns := make([]keysetColumn, len(orders))
+
+	for i, value := range orders {
+		if len(value.SortKeys()) > 0 {
+			return nil, nil
+		}
+
+		column, resolvedPath := th.resolveOrderColumn(value, schema)
+
+		orderRequest, err := th.sorters[column.Order].OrderColumn(resolvedPath, column, value.Direction(), locale)
+		if err != nil {
+			return nil, err
+		}
+
+		if orderRequest.SortKeys != nil {
+			return nil, nil
+		}
+
+		columns[i] = keysetColumn{
+			path:       orderRequest.Path,
+			direction:  orderRequest.Dir,
+			nullsFirst: th.dbConnector.QueryBuilder().NullsFirst(orderRequest.Dir),
+		}
+	}
+
+	return &keyset{
+		orders:  orders,
+		columns: columns,
+	}, nil
+}
+
+// Returns the additional select expressions for the sort values.
+func (k keyset) selectColumns() []string {
+	selectColumns := make([]string, len(k.columns))
+	for i, column := range k.columns {
+		selectColumns[i] = column.path + " AS " + keysetAliasPrefix + strconv.Itoa(i)
+	}
+
+	return selectColumns
+}
+
+// Constructs the condition, which only matches rows after the sort values of the keyset. As
+// the directions might be mixed, the condition is expanded, e.g. for (a ASC, b DESC):
+// (a > ?) OR (a = ? AND b < ?)
+// NULL sort values cannot be compared, so they are matched explicitly, according to where the
+// database orders them.
+func (k keyset) predicate(arguments *Arguments) string {
+	var orPredicates []string
+
+	for i, column := range k.columns {
+		// Nothing follows a NULL, if NULLs are ordered last
+		if k.after[i] == nil && !column.nullsFirst {
+			continue
+		}
+
+		andPredicates := make([]string, i+1)
+
+		for j := 0; j < i; j++ {
+			if k.after[j] == nil {
+				andPredicates[j] = k.columns[j].path + " IS NULL"
+			} else {
+				andPredicates[j] = k.columns[j].path + " = " + arguments.Bind(k.after[j])
+			}
+		}
+
+		operator := " > "
+		if column.direction == tableaux.OrderDesc {
+			operator = " < "
+		}
+
+		switch {
+		case k.after[i] == nil:
+			andPredicates[i] = column.path + " IS NOT NULL"
+		case column.nullsFirst:
+			andPredicates[i] = column.path + operator + arguments.Bind(k.after[i])
+		default:
+			andPredicates[i] = "(" + column.path + operator + arguments.Bind(k.after[i]) + " OR " + column.path + " IS NULL)"
+		}
+
+		orPredicates = append(orPredicates, "("+strings.Join(andPredicates, " AND ")+")")
+	}
+
+	if len(orPredicates) == 0 {
+		return "1 = 0"
+	}
+
+	return "(" + strings.Join(orPredicates, " OR ") + ")"
+}
+
+type continuationToken struct {
+	Version int               `json:"v"`
+	Orders  []string          `json:"o"`
+	Values  []json.RawMessage `json:"k"`
+}
+
+// Describes the orders, so a token can only be used for the orders it was issued for.
+func orderFingerprint(orders []datasource.Order) []string {
+	fingerprint := make([]string, len(orders))
+	for i, value := range orders {
+		fingerprint[i] = value.Path() + " " + string(value.Direction())
+	}
+
+	return fingerprint
+}
+
+// Encodes the sort values of the last row into an opaque continuation token.
+func encodeContinuation(orders []datasource.Order, values []interface{}) (string, error) {
+	token := continuationToken{
+		Version: continuationVersion,
+		Orders:  orderFingerprint(orders),
+		Values:  make([]json.RawMessage, len(values)),
+	}
+
+	for i, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+
+		token.Values[i] = encoded
+	}
+
+	encoded, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// Decodes a continuation token back into the sort values, and checks that it was
+// issued for the given orders.
+func decodeContinuation(encoded string, orders []datasource.Order) ([]interface{}, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidContinuation
+	}
+
+	var token continuationToken
+	if err := json.Unmarshal(decoded, &token); err != nil {
+		return nil, ErrInvalidContinuation
+	}
+
+	fingerprint := orderFingerprint(orders)
+	if token.Version != continuationVersion || len(token.Values) != len(fingerprint) ||
+		strings.Join(token.Orders, ",") != strings.Join(fingerprint, ",") {
+		return nil, ErrInvalidContinuation
+	}
+
+	values := make([]interface{}, len(token.Values))
+	for i, rawValue := range token.Values {
+		decoder := json.NewDecoder(bytes.NewReader(rawValue))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&values[i]); err != nil {
+			return nil, ErrInvalidContinuation
+		}
+
+		// Integral numbers must be bound as such, not as strings or floats
+		if number, isNumber := values[i].(json.Number); isNumber {
+			if int64Value, err := number.Int64(); err == nil {
+				values[i] = int64Value
+			} else if values[i], err = number.Float64(); err != nil {
+				return nil, ErrInvalidContinuation
+			}
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,113 @@
+package sqlsource
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/datasource"
+)
+
+func TestKeysetPredicate(t *testing.T) {
+	keys := keyset{
+		columns: []keysetColumn{
+			{path: "person.name", direction: tableaux.OrderAsc, nullsFirst: true},
+			{path: "person.age", direction: tableaux.OrderDesc, nullsFirst: true},
+			{path: "person.person_key", direction: tableaux.OrderAsc, nullsFirst: true},
+		},
+		after: []interface{}{"Bob", int64(42), int64(7)},
+	}
+
+	arguments := NewArguments(numberedQueryBuilder{})
+	predicate := keys.predicate(arguments)
+
+	expectedPredicate := "((person.name > $1) OR (person.name = $2 AND person.age < $3) OR " +
+		"(person.name = $4 AND person.age = $5 AND person.person_key > $6))"
+	if predicate != expectedPredicate {
+		t.Errorf("keyset.predicate was incorrect, got: %s, want: %s.", predicate, expectedPredicate)
+	}
+
+	expectedArgs := []interface{}{"Bob", "Bob", int64(42), "Bob", int64(42), int64(7)}
+	if !reflect.DeepEqual(arguments.Values(), expectedArgs) {
+		t.Errorf("keyset.predicate bound incorrect arguments, got: %v, want: %v.", arguments.Values(), expectedArgs)
+	}
+}
+
+func TestKeysetPredicateNulls(t *testing.T) {
+	tables := []struct {
+		nullsFirst bool
+		after      []interface{}
+		predicate  string
+		args       []interface{}
+	}{
+		{
+			true, []interface{}{nil, int64(7)},
+			"((person.name IS NOT NULL) OR (person.name IS NULL AND person.person_key > $1))", []interface{}{int64(7)},
+		},
+		{
+			false, []interface{}{nil, int64(7)},
+			"((person.name IS NULL AND (person.person_key > $1 OR person.person_key IS NULL)))", []interface{}{int64(7)},
+		},
+		{
+			false, []interface{}{"Bob", int64(7)},
+			"(((person.name > $1 OR person.name IS NULL)) OR (person.name = $2 AND (person.person_key > $3 OR person.person_key IS NULL)))",
+			[]interface{}{"Bob", "Bob", int64(7)},
+		},
+	}
+
+	for _, table := range tables {
+		keys := keyset{
+			columns: []keysetColumn{
+				{path: "person.name", direction: tableaux.OrderAsc, nullsFirst: table.nullsFirst},
+				{path: "person.person_key", direction: tableaux.OrderAsc, nullsFirst: table.nullsFirst},
+			},
+			after: table.after,
+		}
+
+		arguments := NewArguments(numberedQueryBuilder{})
+		predicate := keys.predicate(arguments)
+
+		if predicate != table.predicate {
+			t.Errorf("keyset.predicate(%v) was incorrect, got: %s, want: %s.", table.after, predicate, table.predicate)
+		}
+
+		if !reflect.DeepEqual(arguments.Values(), table.args) {
+			t.Errorf("keyset.predicate(%v) bound incorrect arguments, got: %v, want: %v.", table.after, arguments.Values(), table.args)
+		}
+	}
+}
+
+func TestContinuationRoundTrip(t *testing.T) {
+	orders := []datasource.Order{
+		datasource.NewOrder("person_name", tableaux.OrderAsc, nil),
+		datasource.NewOrder("person_personKey", tableaux.OrderAsc, nil),
+	}
+	values := []interface{}{"Bob", int64(7)}
+
+	token, err := encodeContinuation(orders, values)
+	if err != nil {
+		t.Fatalf("encodeContinuation failed: %s", err)
+	}
+
+	decoded, err := decodeContinuation(token, orders)
+	if err != nil {
+		t.Fatalf("decodeContinuation failed: %s", err)
+	}
+
+	if !reflect.DeepEqual(decoded, values) {
+		t.Errorf("Continuation round trip was incorrect, got: %v, want: %v.", decoded, values)
+	}
+
+	otherOrders := []datasource.Order{
+		datasource.NewOrder("person_name", tableaux.OrderDesc, nil),
+		datasource.NewOrder("person_personKey", tableaux.OrderAsc, nil),
+	}
+
+	if _, err := decodeContinuation(token, otherOrders); err != ErrInvalidContinuation {
+		t.Errorf("decodeContinuation with different orders was incorrect, got: %v, want: %v.", err, ErrInvalidContinuation)
+	}
+
+	if _, err := decodeContinuation("not a token", orders); err != ErrInvalidContinuation {
+		t.Errorf("decodeContinuation of garbage was incorrect, got: %v, want: %v.", err, ErrInvalidContinuation)
+	}
+}
@@ -0,0 +1,136 @@
+package sqlsource
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// QueryKind describes the purpose of a query, which was executed by the Connector.
+type QueryKind string
+
+const (
+	// QueryData is the query fetching the actual data of a request.
+	QueryData QueryKind = "data"
+
+	// QueryPrimaryKeys is the query fetching the primary keys of a request for deferred loading.
+	QueryPrimaryKeys QueryKind = "primaryKeys"
+
+	// QueryTotalCount is the query counting all rows of an entity.
+	QueryTotalCount QueryKind = "totalCount"
+
+	// QueryFilteredCount is the query counting the rows matching the filters and the search.
+	QueryFilteredCount QueryKind = "filteredCount"
+
+	// QueryFacets is the query fetching the facets of a path.
+	QueryFacets QueryKind = "facets"
+
+	// QueryAggregation is the query fetching an aggregation.
+	QueryAggregation QueryKind = "aggregation"
+)
+
+// QueryEvent describes a single query, which was executed by the Connector.
+type QueryEvent struct {
+	// The purpose of the query.
+	Kind QueryKind
+
+	// The entity of the schema, which the query was executed for.
+	Entity string
+
+	// The generated SQL, and its bound arguments.
+	Query     string
+	Arguments []interface{}
+
+	// The time from preparing the query, until its results were fully read.
+	Duration time.Duration
+
+	// The number of rows read. For count queries, this is the counted number of rows instead.
+	Rows uint64
+
+	// The error of the query, if it failed.
+	Err error
+}
+
+// Observer is notified about the queries executed by a Connector, e.g. to record metrics or
+// traces. Observers are called synchronously, and must therefore return quickly.
+type Observer interface {
+	// ObserveQuery is called once a query finished. The context carries the values of the
+	// request, which caused the query. Identical data requests share a single execution, of
+	// which the queries carry the values of the request, which started it.
+	ObserveQuery(ctx context.Context, event QueryEvent)
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as Observer.
+type ObserverFunc func(ctx context.Context, event QueryEvent)
+
+// ObserveQuery calls f(ctx, event).
+func (f ObserverFunc) ObserveQuery(ctx context.Context, event QueryEvent) {
+	f(ctx, event)
+}
+
+// The observers registered on a Connector. As the Connector is passed by value, they are kept
+// behind a pointer, so all copies share them.
+type observerRegistry struct {
+	mutex     sync.RWMutex
+	observers []Observer
+}
+
+func (r *observerRegistry) add(observer Observer) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.observers = append(r.observers, observer)
+}
+
+func (r *observerRegistry) notify(ctx context.Context, event QueryEvent) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, observer := range r.observers {
+		observer.ObserveQuery(ctx, event)
+	}
+}
+
+// Tracks a single query, until its results are fully read.
+type queryObservation struct {
+	ctx      context.Context
+	registry *observerRegistry
+	event    QueryEvent
+	start    time.Time
+	finished bool
+}
+
+// AddObserver registers an Observer, which is notified about all queries executed by the
+// Connector from now on.
+func (th Connector) AddObserver(observer Observer) {
+	th.observers.add(observer)
+}
+
+// Starts tracking a query, which is about to be executed.
+func (th Connector) observeQuery(ctx context.Context, kind QueryKind, entity, query string, arguments []interface{}) *queryObservation {
+	return &queryObservation{
+		ctx:      ctx,
+		registry: th.observers,
+		event: QueryEvent{
+			Kind:      kind,
+			Entity:    entity,
+			Query:     query,
+			Arguments: arguments,
+		},
+		start: time.Now(),
+	}
+}
+
+// Notifies the observers, that the query finished. Only the first call has an effect.
+func (o *queryObservation) finish(rows uint64, err error) {
+	if o == nil || o.finished {
+		return
+	}
+
+	o.finished = true
+	o.event.Duration = time.Since(o.start)
+	o.event.Rows = rows
+	o.event.Err = err
+
+	o.registry.notify(o.ctx, o.event)
+}
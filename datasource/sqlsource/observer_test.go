@@ -0,0 +1,35 @@
+package sqlsource
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestQueryObservationFinishesOnce(t *testing.T) {
+	connector := Connector{observers: &observerRegistry{}}
+
+	var events []QueryEvent
+	connector.AddObserver(ObserverFunc(func(ctx context.Context, event QueryEvent) {
+		events = append(events, event)
+	}))
+
+	queryErr := errors.New("failed")
+
+	observation := connector.observeQuery(context.Background(), QueryTotalCount, "person", "SELECT count(person.id) FROM person", nil)
+	observation.finish(0, queryErr)
+	observation.finish(42, nil)
+
+	if len(events) != 1 {
+		t.Fatalf("Events were incorrect, got: %d, want: 1.", len(events))
+	}
+
+	event := events[0]
+	if event.Kind != QueryTotalCount || event.Entity != "person" || event.Rows != 0 || event.Err != queryErr {
+		t.Errorf("Event was incorrect, got: %+v.", event)
+	}
+
+	// Finishing an untracked query has no effect
+	var untracked *queryObservation
+	untracked.finish(0, nil)
+}
@@ -0,0 +1,71 @@
+package sqlsource
+
+import (
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/filter"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/order"
+)
+
+// Option configures a Connector on creation.
+type Option func(connector *Connector)
+
+// WithPathResolver registers a PathResolver, which columns can reference by name. A built-in
+// PathResolver of the same name is replaced.
+func WithPathResolver(name string, resolver datasource.PathResolver) Option {
+	return func(connector *Connector) {
+		connector.resolvers[name] = resolver
+	}
+}
+
+// WithSorter registers a Sorter, which columns can reference as order by name. A built-in
+// Sorter of the same name is replaced.
+func WithSorter(name string, sorter order.Sorter) Option {
+	return func(connector *Connector) {
+		connector.sorters[name] = sorter
+	}
+}
+
+// WithFilter registers a Filter, which columns can reference by name. A built-in Filter of
+// the same name is replaced.
+func WithFilter(name string, columnFilter filter.Filter) Option {
+	return func(connector *Connector) {
+		connector.filters[name] = columnFilter
+	}
+}
+
+// WithObserver registers an Observer, which is notified about all queries executed by the
+// Connector.
+func WithObserver(observer Observer) Option {
+	return func(connector *Connector) {
+		connector.observers.add(observer)
+	}
+}
+
+// WithClock replaces the clock, which relative dates in filters are resolved against. It
+// defaults to time.Now.
+func WithClock(clock dates.Clock) Option {
+	return func(connector *Connector) {
+		connector.clock = clock
+	}
+}
+
+// Collects the names of all registered filters, orders and path resolvers.
+func (th Connector) references() config.ColumnReferences {
+	var references config.ColumnReferences
+
+	for name := range th.filters {
+		references.Filters = append(references.Filters, name)
+	}
+
+	for name := range th.sorters {
+		references.Orders = append(references.Orders, name)
+	}
+
+	for name := range th.resolvers {
+		references.PathResolvers = append(references.PathResolvers, name)
+	}
+
+	return references
+}
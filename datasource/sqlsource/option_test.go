@@ -0,0 +1,120 @@
+package sqlsource
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/filter"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/order"
+)
+
+type lowerResolver struct{}
+
+func (lowerResolver) ResolvePathName(column config.TableSchemaColumn) string {
+	return "LOWER(" + column.Path + ")"
+}
+
+func newOptionsTestConnector(t *testing.T, options ...Option) (*Connector, error) {
+	translator, err := config.NewTranslatorFromFolder(filepath.Join("testfiles", "i18n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaMapper, err := config.NewSchemaMapperFromFolder(filepath.Join("testfiles", "schema"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connector, err := NewConnector(nil, config.EnumMapper{}, translator, schemaMapper, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return connector.(*Connector), nil
+}
+
+func TestNewConnectorUnknownReference(t *testing.T) {
+	_, err := newOptionsTestConnector(t)
+
+	if _, isReferenceErr := err.(*config.UnknownColumnReferenceError); !isReferenceErr {
+		t.Fatalf("Error was incorrect, got: %v, want: unknown reference.", err)
+	}
+
+	if err.Error() != "Unknown filter SoundexFilter in column person_name of schema person" {
+		t.Errorf("Error message was incorrect, got: %s.", err)
+	}
+}
+
+func TestNewConnectorOptions(t *testing.T) {
+	connector, err := newOptionsTestConnector(t,
+		WithFilter("SoundexFilter", filter.PlainString{Common: &filter.Common{}}),
+		WithSorter("CollatedOrder", order.Direct{}),
+		WithPathResolver("LowerPathResolver", lowerResolver{}),
+		// Overrides the built-in NumericFilter
+		WithFilter("NumericFilter", filter.Boolean{Common: &filter.Common{}}),
+		WithObserver(ObserverFunc(func(ctx context.Context, event QueryEvent) {})),
+	)
+	if err != nil {
+		t.Fatalf("NewConnector failed: %s", err)
+	}
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id", "person_name").
+		Filters(
+			datasource.NewSimpleFilterGroup("person_id", tableaux.FilterEquals, []interface{}{true}),
+			datasource.NewSimpleFilterGroup("person_name", tableaux.FilterEquals, []interface{}{"Alice"}),
+		).
+		Orders(datasource.NewOrder("person_name", tableaux.OrderAsc, nil)).
+		Locale("en").
+		Build()
+
+	if err := connector.ValidateRequest(request); err != nil {
+		t.Errorf("ValidateRequest failed: %s", err)
+	}
+
+	schema, err := connector.schemaMapper.ResolvedSchema("persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, resolvedPath := connector.resolveOrderColumn(request.Orders()[0], schema)
+	if resolvedPath != "LOWER(person_name)" {
+		t.Errorf("Resolved path was incorrect, got: %s, want: LOWER(person_name).", resolvedPath)
+	}
+
+	if observers := len(connector.observers.observers); observers != 1 {
+		t.Errorf("Observers were incorrect, got: %d, want: 1.", observers)
+	}
+}
+
+func TestNewConnectorClock(t *testing.T) {
+	now := time.Date(2018, 3, 21, 23, 30, 0, 0, time.UTC)
+
+	connector, err := newOptionsTestConnector(t,
+		WithFilter("SoundexFilter", filter.PlainString{Common: &filter.Common{}}),
+		WithSorter("CollatedOrder", order.Direct{}),
+		WithPathResolver("LowerPathResolver", lowerResolver{}),
+		WithClock(func() time.Time { return now }),
+	)
+	if err != nil {
+		t.Fatalf("NewConnector failed: %s", err)
+	}
+
+	environment, err := connector.environment("Europe/Berlin", "en")
+	if err != nil {
+		t.Fatalf("Creating environment failed: %s", err)
+	}
+
+	if !environment.Now.Equal(now) || environment.Now.Location().String() != "Europe/Berlin" {
+		t.Errorf("Environment time was incorrect, got: %s, want: %s in Europe/Berlin.", environment.Now, now)
+	}
+
+	if environment.Locale != "en" {
+		t.Errorf("Environment locale was incorrect, got: %s, want: en.", environment.Locale)
+	}
+}
@@ -12,21 +12,69 @@ import (
 	"github.com/tableaux-project/tableaux"
 	"github.com/tableaux-project/tableaux/config"
 	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
 	"github.com/tableaux-project/tableaux/datasource/sqlsource/filter"
 	"github.com/tableaux-project/tableaux/datasource/sqlsource/order"
 )
 
+// QueryBuilder encapsulates the database specific syntax for constructing queries.
+// Values are never inlined into the query string. Instead, every value is bound via
+// Arguments, which in turn asks the QueryBuilder for the placeholder to use.
 type QueryBuilder interface {
+	// Placeholder returns the placeholder for the bind argument at the given
+	// (1-based) position, e.g. "?" or "$1".
+	Placeholder(position int) string
+
 	ResolvedToJoinString(resolved Join) string
 	CountJoinToJoinString(join CountJoin) string
 	IfNull(query string, then interface{}) string
-	SelectWithLimitQuery(query string) string
+	// SelectWithLimitQuery turns the query into a select, which is limited to the amount of rows
+	// bound to limitPlaceholder. The offsetPlaceholder is empty, if no offset is to be applied.
+	SelectWithLimitQuery(query string, limitPlaceholder, offsetPlaceholder string) string
 
 	OrderColumn(path string, direction tableaux.Order) string
-	OrderColumnByArray(column string, values []interface{}, direction tableaux.Order) string
+	OrderColumnByArray(column string, values []interface{}, direction tableaux.Order, arguments *Arguments) string
+	// NullsFirst reports whether the database orders NULL values before all other values in the
+	// given direction.
+	NullsFirst(direction tableaux.Order) bool
+
+	FilterStringFromValues(path string, filter filter.Filter, operator filter.Operator, values []interface{}, arguments *Arguments) (string, error)
+	FilterStringFromValue(path string, operator filter.Operator, placeholder string) string
+	// FilterStringBetween constructs the condition for a path to lie within the inclusive range
+	// of the bound values.
+	FilterStringBetween(path string, fromPlaceholder, toPlaceholder string) string
+
+	// ExplainQuery turns the query into one, which returns the execution plan of the database
+	// for it. The query keeps its placeholders.
+	ExplainQuery(query string) string
+}
+
+// Arguments collects the values which are bound to a query, in the order of their
+// placeholders in the query string. Because of this, query parts must be assembled
+// in the same order in which they appear in the final query.
+type Arguments struct {
+	queryBuilder QueryBuilder
+	values       []interface{}
+}
+
+// NewArguments creates a new, empty Arguments instance, which uses the placeholders
+// of the given QueryBuilder.
+func NewArguments(queryBuilder QueryBuilder) *Arguments {
+	return &Arguments{
+		queryBuilder: queryBuilder,
+	}
+}
 
-	FilterStringFromValues(path string, filter filter.Filter, operator filter.Operator, values []interface{}) (string, error)
-	FilterStringFromValue(path string, operator filter.Operator, value string) string
+// Bind appends a value to the arguments, and returns the placeholder which must
+// be used in the query string for the value.
+func (arguments *Arguments) Bind(value interface{}) string {
+	arguments.values = append(arguments.values, value)
+	return arguments.queryBuilder.Placeholder(len(arguments.values))
+}
+
+// Values returns all bound values, in order of binding.
+func (arguments *Arguments) Values() []interface{} {
+	return arguments.values
 }
 
 // Checks if two string slices are equal.
@@ -44,7 +92,9 @@ func stringSlicesEqual(a, b sort.StringSlice) bool {
 	return true
 }
 
-func OrderColumn(queryBuilder QueryBuilder, path string, column config.TableSchemaColumn, sorter order.Sorter, order datasource.Order, locale string) string {
+// OrderColumn constructs the order clause for a single column. Predefined sort keys of the order
+// take precedence over the sorter of the column.
+func OrderColumn(queryBuilder QueryBuilder, path string, column config.TableSchemaColumn, sorter order.Sorter, order datasource.Order, locale string, arguments *Arguments) (string, error) {
 	predefinedSortKeys := order.SortKeys()
 
 	if len(predefinedSortKeys) > 0 {
@@ -83,63 +133,91 @@ func OrderColumn(queryBuilder QueryBuilder, path string, column config.TableSche
 			sort.Sort(sortedEntries)
 			if stringSlicesEqual(sanitizedKeys, sortedEntries) {
 				// Nice, order does not change. So we can fall back to simple ordering
-				return queryBuilder.OrderColumn(path, order.Direction())
+				return queryBuilder.OrderColumn(path, order.Direction()), nil
 			}
 
 			// Prepare reversed order
-			reversedEntries := sort.Reverse(sortedEntries).(sort.StringSlice)
-			sort.Sort(reversedEntries)
+			reversedEntries := make(sort.StringSlice, len(sortedEntries))
+			copy(reversedEntries, sortedEntries)
+			sort.Sort(sort.Reverse(reversedEntries))
 
 			// Check again - the order might just need to be reversed
 			if stringSlicesEqual(sanitizedKeys, reversedEntries) {
 				// Nice, order does not change. So we can fall back to simple ordering
-				return queryBuilder.OrderColumn(path, order.Direction().Reverse())
+				return queryBuilder.OrderColumn(path, order.Direction().Reverse()), nil
 			}
 
 			// Oh well, order is not linear - so fall back to case'd sort.
-			return queryBuilder.OrderColumnByArray(path, predefinedSortKeys, order.Direction())
+			return queryBuilder.OrderColumnByArray(path, predefinedSortKeys, order.Direction(), arguments), nil
 		}
 	}
 
 	orderRequest, err := sorter.OrderColumn(path, column, order.Direction(), locale)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 
 	if orderRequest.SortKeys != nil {
-		return queryBuilder.OrderColumnByArray(orderRequest.Path, orderRequest.SortKeys, orderRequest.Dir)
+		return queryBuilder.OrderColumnByArray(orderRequest.Path, orderRequest.SortKeys, orderRequest.Dir, arguments), nil
 	}
 
-	return queryBuilder.OrderColumn(orderRequest.Path, orderRequest.Dir)
+	return queryBuilder.OrderColumn(orderRequest.Path, orderRequest.Dir), nil
 }
 
-func FilterColumn(queryBuilder QueryBuilder, path string, filtery filter.Filter, filterGroups []datasource.FilterGroup) (string, error) {
+// FilterColumn constructs the condition for all FilterGroups of a single path. The filters of
+// each FilterGroup are OR'ed, while the FilterGroups themselves are AND'ed. Each FilterGroup is
+// parenthesized, so the condition can safely be combined with other conditions. Values of a
+// filter.RangeFilter are interpreted in the given environment of the request.
+func FilterColumn(queryBuilder QueryBuilder, path string, filtery filter.Filter, filterGroups []datasource.FilterGroup,
+	environment dates.Environment, arguments *Arguments) (string, error) {
+	if rangeFilter, isRangeFilter := filtery.(filter.RangeFilter); isRangeFilter {
+		return rangeFilterColumn(queryBuilder, path, rangeFilter, filterGroups, environment, arguments)
+	}
+
 	var andFilters []string
 	for _, filterGroup := range filterGroups {
 		// First, we group all filter with the same operator together. This is done, so we can optimize
 		// some cases (e.g. multiple EQUALS can be pulled into an IN clause)
+		var operators []filter.Operator
 		filterModeMap := make(map[filter.Operator][]interface{})
 		for _, filterGroupFilter := range filterGroup.Filters() {
 			operator, err := filtery.Operator(filterGroupFilter.Value(), filterGroupFilter.FilterMode())
 			if err != nil {
 				return "", err
 			}
-			filterModeMap[operator] = append(filterModeMap[operator], filterGroupFilter.Value())
+
+			values, err := filterValues(filterGroupFilter)
+			if err != nil {
+				return "", err
+			}
+
+			if _, exists := filterModeMap[operator]; !exists {
+				operators = append(operators, operator)
+			}
+
+			if operator == filter.OperatorBetween {
+				// The bounds are kept together, so each range results in its own condition
+				filterModeMap[operator] = append(filterModeMap[operator], values)
+			} else {
+				filterModeMap[operator] = append(filterModeMap[operator], values...)
+			}
 		}
 
-		i := 0
-		orFilters := make([]string, len(filterModeMap))
-		for filterMode, values := range filterModeMap {
-			orFilter, err := queryBuilder.FilterStringFromValues(path, filtery, filterMode, values)
+		if len(operators) == 0 {
+			continue
+		}
+
+		orFilters := make([]string, len(operators))
+		for i, operator := range operators {
+			orFilter, err := queryBuilder.FilterStringFromValues(path, filtery, operator, filterModeMap[operator], arguments)
 			if err != nil {
 				return "", err
 			}
 
 			orFilters[i] = orFilter
-			i++
 		}
 
-		andFilters = append(andFilters, strings.Join(orFilters, " OR "))
+		andFilters = append(andFilters, "("+strings.Join(orFilters, " OR ")+")")
 	}
 
 	return strings.Join(andFilters, " AND "), nil
@@ -148,20 +226,30 @@ func FilterColumn(queryBuilder QueryBuilder, path string, filtery filter.Filter,
 type CommonQueryBuilder struct {
 }
 
+// Placeholder returns the question mark placeholder, which is used by most databases.
+func (commonBuilder CommonQueryBuilder) Placeholder(_ int) string {
+	return "?"
+}
+
+// ExplainQuery prefixes the query with EXPLAIN, which is understood by most databases.
+func (commonBuilder CommonQueryBuilder) ExplainQuery(query string) string {
+	return "EXPLAIN " + query
+}
+
 func (commonBuilder CommonQueryBuilder) OrderColumn(path string, direction tableaux.Order) string {
 	return path + " " + string(direction)
 }
 
-func (commonBuilder CommonQueryBuilder) OrderColumnByArray(path string, values []interface{}, direction tableaux.Order) string {
+// NullsFirst considers NULL values smaller than all other values, like e.g. MySQL and SQLite do.
+func (commonBuilder CommonQueryBuilder) NullsFirst(direction tableaux.Order) bool {
+	return direction != tableaux.OrderDesc
+}
+
+func (commonBuilder CommonQueryBuilder) OrderColumnByArray(path string, values []interface{}, direction tableaux.Order, arguments *Arguments) string {
 	cases := make([]string, len(values))
 
 	for index, value := range values {
-		switch value.(type) {
-		default:
-			cases[index] = fmt.Sprintf("WHEN %v THEN %d", value, index)
-		case string:
-			cases[index] = fmt.Sprintf("WHEN '%s' THEN %d", value, index)
-		}
+		cases[index] = fmt.Sprintf("WHEN %s THEN %d", arguments.Bind(value), index)
 	}
 
 	return fmt.Sprintf("CASE %s %s ELSE -1 END %s", path, strings.Join(cases, " "), string(direction))
@@ -182,28 +270,63 @@ func (commonBuilder CommonQueryBuilder) CountJoinToJoinString(resolvedCount Coun
 
 // Constructs a single filter expression for a path from multiple values
 // multiple values are expected to be OR chained.
-func (commonBuilder CommonQueryBuilder) FilterStringFromValues(path string, filtery filter.Filter, operator filter.Operator, values []interface{}) (string, error) {
-	parsedValues := parseValues(filtery, values)
+func (commonBuilder CommonQueryBuilder) FilterStringFromValues(path string, filtery filter.Filter, operator filter.Operator, values []interface{}, arguments *Arguments) (string, error) {
+	switch operator {
+	case filter.OperatorIsNull, filter.OperatorIsNotNull:
+		return commonBuilder.FilterStringFromValue(path, operator, ""), nil
+	case filter.OperatorBetween:
+		orChainedRanges := make([]string, len(values))
+		for i, value := range values {
+			bounds, err := parseValues(filtery, value.([]interface{}))
+			if err != nil {
+				return "", err
+			}
+
+			orChainedRanges[i] = commonBuilder.FilterStringBetween(path, arguments.Bind(bounds[0]), arguments.Bind(bounds[1]))
+		}
+
+		return strings.Join(orChainedRanges, " OR "), nil
+	case filter.OperatorContains, filter.OperatorStartsWith, filter.OperatorEndsWith:
+		// The terms are matched literally, so they are not parsed by the filter, which could
+		// introduce wildcards
+		orChainedPatterns := make([]string, len(values))
+		for i, value := range values {
+			term, isString := value.(string)
+			if !isString {
+				return "", fmt.Errorf("cannot parse value %v as string", value)
+			}
+
+			orChainedPatterns[i] = commonBuilder.FilterStringFromValue(path, filter.OperatorLike, arguments.Bind(filter.LikePattern(operator, term)))
+		}
+
+		return strings.Join(orChainedPatterns, " OR "), nil
+	}
+
+	parsedValues, err := parseValues(filtery, values)
+	if err != nil {
+		return "", err
+	}
 
 	if len(values) == 1 {
-		return commonBuilder.FilterStringFromValue(path, operator, parsedValues[0]), nil
+		return commonBuilder.FilterStringFromValue(path, operator, arguments.Bind(parsedValues[0])), nil
 	}
 
 	switch operator {
 	case filter.OperatorEqual:
-		return fmt.Sprintf("%s IN (%s)", path, strings.Join(parsedValues, ",")), nil
+		return fmt.Sprintf("%s IN (%s)", path, bindValues(arguments, parsedValues)), nil
 	case filter.OperatorNotEqual:
-		return fmt.Sprintf("%s NOT IN (%s)", path, strings.Join(parsedValues, ",")), nil
+		return fmt.Sprintf("%s NOT IN (%s)", path, bindValues(arguments, parsedValues)), nil
 	case filter.OperatorGreater,
 		filter.OperatorGreaterEquals,
 		filter.OperatorLesser,
 		filter.OperatorLesserEquals,
-		filter.OperatorLike:
+		filter.OperatorLike,
+		filter.OperatorNotLike:
 		// There is no IN or NOT IN we can apply to these filter modes, so we classically OR join them
 		orChainedValues := make([]string, len(values))
 
 		for i, value := range parsedValues {
-			orChainedValues[i] = commonBuilder.FilterStringFromValue(path, operator, value)
+			orChainedValues[i] = commonBuilder.FilterStringFromValue(path, operator, arguments.Bind(value))
 		}
 
 		return strings.Join(orChainedValues, " OR "), nil
@@ -212,16 +335,160 @@ func (commonBuilder CommonQueryBuilder) FilterStringFromValues(path string, filt
 	}
 }
 
-func parseValues(filter filter.Filter, values []interface{}) []string {
-	parsedValues := make([]string, len(values))
+// Constructs the condition for all FilterGroups of a path with a filter.RangeFilter. As each value
+// matches a range, the filters cannot be merged, and are OR'ed one by one instead.
+func rangeFilterColumn(queryBuilder QueryBuilder, path string, rangeFilter filter.RangeFilter, filterGroups []datasource.FilterGroup,
+	environment dates.Environment, arguments *Arguments) (string, error) {
+	var andFilters []string
+	for _, filterGroup := range filterGroups {
+		if len(filterGroup.Filters()) == 0 {
+			continue
+		}
+
+		orFilters := make([]string, len(filterGroup.Filters()))
+		for i, groupFilter := range filterGroup.Filters() {
+			orFilter, err := rangeFilterCondition(queryBuilder, path, rangeFilter, groupFilter, environment, arguments)
+			if err != nil {
+				return "", err
+			}
+
+			orFilters[i] = orFilter
+		}
+
+		andFilters = append(andFilters, "("+strings.Join(orFilters, " OR ")+")")
+	}
+
+	return strings.Join(andFilters, " AND "), nil
+}
+
+// Constructs the condition of a single filter with a filter.RangeFilter.
+func rangeFilterCondition(queryBuilder QueryBuilder, path string, rangeFilter filter.RangeFilter, groupFilter datasource.Filter,
+	environment dates.Environment, arguments *Arguments) (string, error) {
+	operator, err := rangeFilter.Operator(groupFilter.Value(), groupFilter.FilterMode())
+	if err != nil {
+		return "", err
+	}
+
+	values, err := filterValues(groupFilter)
+	if err != nil {
+		return "", err
+	}
+
+	valueRanges := make([]filter.Range, len(values))
+	for i, value := range values {
+		if valueRanges[i], err = rangeFilter.Range(value, environment); err != nil {
+			return "", err
+		}
+	}
+
+	switch groupFilter.FilterMode() {
+	case tableaux.FilterIsNull, tableaux.FilterIsNotNull:
+		return queryBuilder.FilterStringFromValue(path, operator, ""), nil
+	case tableaux.FilterBetween:
+		// Ranges from the start of the lower bound, up to the end of the upper bound
+		return rangeFilterString(path, tableaux.FilterEquals, filter.Range{
+			From: valueRanges[0].From,
+			To:   valueRanges[1].To,
+		}, arguments), nil
+	case tableaux.FilterIn:
+		inFilters := make([]string, len(valueRanges))
+		for i, valueRange := range valueRanges {
+			inFilters[i] = rangeFilterString(path, tableaux.FilterEquals, valueRange, arguments)
+		}
+
+		return "(" + strings.Join(inFilters, " OR ") + ")", nil
+	default:
+		return rangeFilterString(path, groupFilter.FilterMode(), valueRanges[0], arguments), nil
+	}
+}
+
+// Constructs the condition of a single filter mode on a range. Values equal to the range lie
+// within it, greater values after it, and lesser values before it.
+func rangeFilterString(path string, filterMode tableaux.FilterMode, valueRange filter.Range, arguments *Arguments) string {
+	switch filterMode {
+	case tableaux.FilterNotEquals:
+		return fmt.Sprintf("(%s < %s OR %s >= %s)", path, arguments.Bind(valueRange.From), path, arguments.Bind(valueRange.To))
+	case tableaux.FilterGreater:
+		return fmt.Sprintf("%s >= %s", path, arguments.Bind(valueRange.To))
+	case tableaux.FilterGreaterEquals:
+		return fmt.Sprintf("%s >= %s", path, arguments.Bind(valueRange.From))
+	case tableaux.FilterLesser:
+		return fmt.Sprintf("%s < %s", path, arguments.Bind(valueRange.From))
+	case tableaux.FilterLesserEquals:
+		return fmt.Sprintf("%s < %s", path, arguments.Bind(valueRange.To))
+	default:
+		return fmt.Sprintf("(%s >= %s AND %s < %s)", path, arguments.Bind(valueRange.From), path, arguments.Bind(valueRange.To))
+	}
+}
+
+// Splits the value of a filter into the values, which are parsed by the filter of its column.
+// IN filters hold a non-empty list of values, BETWEEN filters a list of exactly two values, and
+// null checks no value at all.
+func filterValues(groupFilter datasource.Filter) ([]interface{}, error) {
+	filterMode := groupFilter.FilterMode()
+
+	switch filterMode {
+	case tableaux.FilterIsNull, tableaux.FilterIsNotNull:
+		return nil, nil
+	case tableaux.FilterIn:
+		values, isList := groupFilter.Value().([]interface{})
+		if !isList || len(values) == 0 {
+			return nil, fmt.Errorf("filter mode %s requires a non-empty list of values", filterMode)
+		}
+
+		return values, nil
+	case tableaux.FilterBetween:
+		values, isList := groupFilter.Value().([]interface{})
+		if !isList || len(values) != 2 {
+			return nil, fmt.Errorf("filter mode %s requires a list of two values", filterMode)
+		}
+
+		return values, nil
+	default:
+		return []interface{}{groupFilter.Value()}, nil
+	}
+}
+
+func parseValues(filter filter.Filter, values []interface{}) ([]interface{}, error) {
+	parsedValues := make([]interface{}, len(values))
 
 	for i, value := range values {
-		parsedValues[i] = filter.ParseValue(value)
+		parsedValue, err := filter.ParseValue(value)
+		if err != nil {
+			return nil, err
+		}
+
+		parsedValues[i] = parsedValue
+	}
+
+	return parsedValues, nil
+}
+
+// Binds all values, and returns their comma separated placeholders.
+func bindValues(arguments *Arguments, values []interface{}) string {
+	placeholders := make([]string, len(values))
+
+	for i, value := range values {
+		placeholders[i] = arguments.Bind(value)
 	}
 
-	return parsedValues
+	return strings.Join(placeholders, ",")
+}
+
+// FilterStringFromValue constructs the condition of a single operator. Null checks ignore the
+// placeholder. LIKE patterns escape their wildcards by filter.LikeEscape, which is declared
+// explicitly, as databases differ in their default escape character.
+func (commonBuilder CommonQueryBuilder) FilterStringFromValue(path string, operator filter.Operator, placeholder string) string {
+	switch operator {
+	case filter.OperatorIsNull, filter.OperatorIsNotNull:
+		return fmt.Sprintf("%s %s", path, operator)
+	case filter.OperatorLike, filter.OperatorNotLike:
+		return fmt.Sprintf("%s %s %s ESCAPE '%s'", path, operator, placeholder, filter.LikeEscape)
+	default:
+		return fmt.Sprintf("%s %s %s", path, operator, placeholder)
+	}
 }
 
-func (commonBuilder CommonQueryBuilder) FilterStringFromValue(path string, operator filter.Operator, value string) string {
-	return fmt.Sprintf("%s %s %s", path, operator, value)
+func (commonBuilder CommonQueryBuilder) FilterStringBetween(path string, fromPlaceholder, toPlaceholder string) string {
+	return fmt.Sprintf("%s BETWEEN %s AND %s", path, fromPlaceholder, toPlaceholder)
 }
@@ -0,0 +1,324 @@
+package sqlsource
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/dates"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/filter"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/order"
+)
+
+// numberedQueryBuilder is a QueryBuilder using PostgreSQL style placeholders.
+type numberedQueryBuilder struct {
+	CommonQueryBuilder
+}
+
+func (builder numberedQueryBuilder) Placeholder(position int) string {
+	return "$" + strconv.Itoa(position)
+}
+
+func (builder numberedQueryBuilder) IfNull(query string, then interface{}) string {
+	return query
+}
+
+func (builder numberedQueryBuilder) SelectWithLimitQuery(query string, limitPlaceholder, offsetPlaceholder string) string {
+	if offsetPlaceholder == "" {
+		return "SELECT " + query + " LIMIT " + limitPlaceholder
+	}
+
+	return "SELECT " + query + " LIMIT " + limitPlaceholder + " OFFSET " + offsetPlaceholder
+}
+
+// mysqlQueryBuilder is a QueryBuilder rendering MySQL queries, of which string literals treat a
+// backslash as escape character by default.
+type mysqlQueryBuilder struct {
+	CommonQueryBuilder
+}
+
+func (builder mysqlQueryBuilder) IfNull(query string, then interface{}) string {
+	return fmt.Sprintf("IFNULL(%s, %v)", query, then)
+}
+
+func (builder mysqlQueryBuilder) SelectWithLimitQuery(query string, limitPlaceholder, offsetPlaceholder string) string {
+	if offsetPlaceholder == "" {
+		return "SELECT " + query + " LIMIT " + limitPlaceholder
+	}
+
+	return "SELECT " + query + " LIMIT " + offsetPlaceholder + "," + limitPlaceholder
+}
+
+func TestFilterStringFromValues(t *testing.T) {
+	tables := []struct {
+		builder  QueryBuilder
+		operator filter.Operator
+		values   []interface{}
+		query    string
+		args     []interface{}
+	}{
+		{
+			numberedQueryBuilder{}, filter.OperatorEqual, []interface{}{"O'Brien"},
+			"person.name = $1", []interface{}{"O'Brien"},
+		},
+		{
+			numberedQueryBuilder{}, filter.OperatorEqual, []interface{}{"a", "b'); DROP TABLE person; --"},
+			"person.name IN ($1,$2)", []interface{}{"a", "b'); DROP TABLE person; --"},
+		},
+		{
+			numberedQueryBuilder{}, filter.OperatorNotEqual, []interface{}{"a", "b"},
+			"person.name NOT IN ($1,$2)", []interface{}{"a", "b"},
+		},
+		{
+			numberedQueryBuilder{}, filter.OperatorLike, []interface{}{"a%", "%b"},
+			"person.name LIKE $1 ESCAPE '!' OR person.name LIKE $2 ESCAPE '!'", []interface{}{"a%", "%b"},
+		},
+		{
+			numberedQueryBuilder{}, filter.OperatorGreaterEquals, []interface{}{"a", "b"},
+			"person.name >= $1 OR person.name >= $2", []interface{}{"a", "b"},
+		},
+		{
+			numberedQueryBuilder{}, filter.OperatorLesser, []interface{}{"a"},
+			"person.name < $1", []interface{}{"a"},
+		},
+		{
+			mysqlQueryBuilder{}, filter.OperatorLike, []interface{}{"a%"},
+			"person.name LIKE ? ESCAPE '!'", []interface{}{"a%"},
+		},
+		{
+			mysqlQueryBuilder{}, filter.OperatorNotLike, []interface{}{"a%"},
+			"person.name NOT LIKE ? ESCAPE '!'", []interface{}{"a%"},
+		},
+		{
+			mysqlQueryBuilder{}, filter.OperatorContains, []interface{}{`50%\`},
+			"person.name LIKE ? ESCAPE '!'", []interface{}{`%50!%\%`},
+		},
+	}
+
+	for _, table := range tables {
+		arguments := NewArguments(table.builder)
+
+		query, err := table.builder.FilterStringFromValues("person.name", filter.PlainString{Common: &filter.Common{}},
+			table.operator, table.values, arguments)
+		if err != nil {
+			t.Errorf("FilterStringFromValues(%v) failed: %s", table.values, err)
+			continue
+		}
+
+		if query != table.query {
+			t.Errorf("FilterStringFromValues(%v) was incorrect, got: %s, want: %s.", table.values, query, table.query)
+		}
+
+		if !reflect.DeepEqual(arguments.Values(), table.args) {
+			t.Errorf("FilterStringFromValues(%v) bound incorrect arguments, got: %v, want: %v.", table.values, arguments.Values(), table.args)
+		}
+	}
+}
+
+func TestFilterColumn(t *testing.T) {
+	builder := numberedQueryBuilder{}
+	arguments := NewArguments(builder)
+
+	query, err := FilterColumn(builder, "person.name", filter.PlainString{Common: &filter.Common{}}, []datasource.FilterGroup{
+		datasource.NewFilterGroup("name", []datasource.Filter{
+			datasource.NewFilter(tableaux.FilterEquals, "a"),
+			datasource.NewFilter(tableaux.FilterNotEquals, "b"),
+			datasource.NewFilter(tableaux.FilterEquals, "c"),
+		}),
+		datasource.NewSimpleFilterGroup("name", tableaux.FilterEquals, []interface{}{"d"}),
+	}, dates.NewEnvironment(time.UTC, "", nil), arguments)
+	if err != nil {
+		t.Fatalf("FilterColumn failed: %s", err)
+	}
+
+	want := "(person.name IN ($1,$2) OR person.name != $3) AND (person.name = $4)"
+	if query != want {
+		t.Errorf("FilterColumn was incorrect, got: %s, want: %s.", query, want)
+	}
+
+	wantArgs := []interface{}{"a", "c", "b", "d"}
+	if !reflect.DeepEqual(arguments.Values(), wantArgs) {
+		t.Errorf("FilterColumn bound incorrect arguments, got: %v, want: %v.", arguments.Values(), wantArgs)
+	}
+}
+
+func TestFilterColumnModes(t *testing.T) {
+	stringFilter := filter.PlainString{Common: &filter.Common{}}
+	dateFilter := filter.Date{Common: &filter.Common{}}
+
+	tables := []struct {
+		filter  filter.Filter
+		filters []datasource.Filter
+		query   string
+		args    []interface{}
+	}{
+		{
+			stringFilter, []datasource.Filter{
+				datasource.NewFilter(tableaux.FilterIn, []interface{}{"a", "b"}),
+				datasource.NewFilter(tableaux.FilterEquals, "c"),
+			},
+			"(person.name IN ($1,$2,$3))", []interface{}{"a", "b", "c"},
+		},
+		{
+			stringFilter, []datasource.Filter{
+				datasource.NewFilter(tableaux.FilterBetween, []interface{}{"a", "c"}),
+				datasource.NewFilter(tableaux.FilterIsNull, nil),
+			},
+			"(person.name BETWEEN $1 AND $2 OR person.name IS NULL)", []interface{}{"a", "c"},
+		},
+		{
+			stringFilter, []datasource.Filter{
+				datasource.NewFilter(tableaux.FilterIsNotNull, nil),
+			},
+			"(person.name IS NOT NULL)", nil,
+		},
+		{
+			stringFilter, []datasource.Filter{
+				datasource.NewFilter(tableaux.FilterContains, "50%"),
+				datasource.NewFilter(tableaux.FilterStartsWith, "a_"),
+				datasource.NewFilter(tableaux.FilterEndsWith, `\`),
+			},
+			"(person.name LIKE $1 ESCAPE '!' OR person.name LIKE $2 ESCAPE '!' OR person.name LIKE $3 ESCAPE '!')",
+			[]interface{}{"%50!%%", "a!_%", `%\`},
+		},
+		{
+			dateFilter, []datasource.Filter{
+				datasource.NewFilter(tableaux.FilterIn, []interface{}{"2018-03-24", "2018-12-31"}),
+				datasource.NewFilter(tableaux.FilterIsNull, nil),
+			},
+			"(((person.name >= $1 AND person.name < $2) OR (person.name >= $3 AND person.name < $4)) OR person.name IS NULL)",
+			[]interface{}{"2018-03-24", "2018-03-25", "2018-12-31", "2019-01-01"},
+		},
+		{
+			dateFilter, []datasource.Filter{
+				datasource.NewFilter(tableaux.FilterBetween, []interface{}{"2018-03-24", "2018-12-31"}),
+			},
+			"((person.name >= $1 AND person.name < $2))", []interface{}{"2018-03-24", "2019-01-01"},
+		},
+	}
+
+	for _, table := range tables {
+		builder := numberedQueryBuilder{}
+		arguments := NewArguments(builder)
+
+		query, err := FilterColumn(builder, "person.name", table.filter, []datasource.FilterGroup{
+			datasource.NewFilterGroup("name", table.filters),
+		}, dates.NewEnvironment(time.UTC, "", nil), arguments)
+		if err != nil {
+			t.Errorf("FilterColumn(%v) failed: %s", table.filters, err)
+			continue
+		}
+
+		if query != table.query {
+			t.Errorf("FilterColumn(%v) was incorrect, got: %s, want: %s.", table.filters, query, table.query)
+		}
+
+		if !reflect.DeepEqual(arguments.Values(), table.args) {
+			t.Errorf("FilterColumn(%v) bound incorrect arguments, got: %v, want: %v.", table.filters, arguments.Values(), table.args)
+		}
+	}
+
+	invalid := []datasource.Filter{
+		datasource.NewFilter(tableaux.FilterIn, []interface{}{}),
+		datasource.NewFilter(tableaux.FilterBetween, []interface{}{"a"}),
+		datasource.NewFilter(tableaux.FilterBetween, "a"),
+		datasource.NewFilter(tableaux.FilterContains, 42),
+	}
+
+	for _, invalidFilter := range invalid {
+		builder := numberedQueryBuilder{}
+
+		_, err := FilterColumn(builder, "person.name", stringFilter, []datasource.FilterGroup{
+			datasource.NewFilterGroup("name", []datasource.Filter{invalidFilter}),
+		}, dates.NewEnvironment(time.UTC, "", nil), NewArguments(builder))
+		if err == nil {
+			t.Errorf("FilterColumn(%v) should have failed.", invalidFilter)
+		}
+	}
+}
+
+func TestFilterColumnRange(t *testing.T) {
+	dateFilter := filter.Date{Common: &filter.Common{}}
+
+	tables := []struct {
+		mode  tableaux.FilterMode
+		query string
+		args  []interface{}
+	}{
+		{tableaux.FilterEquals, "((person.birthday >= $1 AND person.birthday < $2) OR (person.birthday >= $3 AND person.birthday < $4))",
+			[]interface{}{"2018-03-24", "2018-03-25", "2018-12-31", "2019-01-01"}},
+		{tableaux.FilterNotEquals, "((person.birthday < $1 OR person.birthday >= $2) OR (person.birthday < $3 OR person.birthday >= $4))",
+			[]interface{}{"2018-03-24", "2018-03-25", "2018-12-31", "2019-01-01"}},
+		{tableaux.FilterGreater, "(person.birthday >= $1 OR person.birthday >= $2)", []interface{}{"2018-03-25", "2019-01-01"}},
+		{tableaux.FilterGreaterEquals, "(person.birthday >= $1 OR person.birthday >= $2)", []interface{}{"2018-03-24", "2018-12-31"}},
+		{tableaux.FilterLesser, "(person.birthday < $1 OR person.birthday < $2)", []interface{}{"2018-03-24", "2018-12-31"}},
+		{tableaux.FilterLesserEquals, "(person.birthday < $1 OR person.birthday < $2)", []interface{}{"2018-03-25", "2019-01-01"}},
+	}
+
+	for _, table := range tables {
+		builder := numberedQueryBuilder{}
+		arguments := NewArguments(builder)
+
+		query, err := FilterColumn(builder, "person.birthday", dateFilter, []datasource.FilterGroup{
+			datasource.NewSimpleFilterGroup("birthday", table.mode, []interface{}{"2018-03-24", "2018-12-31"}),
+		}, dates.NewEnvironment(time.UTC, "", nil), arguments)
+		if err != nil {
+			t.Errorf("FilterColumn(%s) failed: %s", table.mode, err)
+			continue
+		}
+
+		if query != table.query {
+			t.Errorf("FilterColumn(%s) was incorrect, got: %s, want: %s.", table.mode, query, table.query)
+		}
+
+		if !reflect.DeepEqual(arguments.Values(), table.args) {
+			t.Errorf("FilterColumn(%s) bound incorrect arguments, got: %v, want: %v.", table.mode, arguments.Values(), table.args)
+		}
+	}
+}
+
+func TestOrderColumnByArray(t *testing.T) {
+	builder := numberedQueryBuilder{}
+	arguments := NewArguments(builder)
+	arguments.Bind("some filter value")
+
+	query := builder.OrderColumnByArray("person.country", []interface{}{"DE", "AT"}, tableaux.OrderAsc, arguments)
+
+	expectedQuery := "CASE person.country WHEN $2 THEN 0 WHEN $3 THEN 1 ELSE -1 END ASC"
+	if query != expectedQuery {
+		t.Errorf("OrderColumnByArray was incorrect, got: %s, want: %s.", query, expectedQuery)
+	}
+
+	expectedArgs := []interface{}{"some filter value", "DE", "AT"}
+	if !reflect.DeepEqual(arguments.Values(), expectedArgs) {
+		t.Errorf("OrderColumnByArray bound incorrect arguments, got: %v, want: %v.", arguments.Values(), expectedArgs)
+	}
+}
+
+func TestOrderColumnSortKeys(t *testing.T) {
+	tables := []struct {
+		sortKeys []interface{}
+		query    string
+	}{
+		{[]interface{}{"AT", "CH", "DE"}, "person.country ASC"},
+		{[]interface{}{"DE", "CH", "AT"}, "person.country DESC"},
+		{[]interface{}{"DE", "AT", "CH"}, "CASE person.country WHEN $1 THEN 0 WHEN $2 THEN 1 WHEN $3 THEN 2 ELSE -1 END ASC"},
+	}
+
+	for _, table := range tables {
+		builder := numberedQueryBuilder{}
+		orderRequest := datasource.NewOrder("person_country", tableaux.OrderAsc, table.sortKeys)
+
+		query, err := OrderColumn(builder, "person.country", config.TableSchemaColumn{}, order.Direct{}, orderRequest, "en", NewArguments(builder))
+		if err != nil {
+			t.Errorf("OrderColumn(%v) failed: %s", table.sortKeys, err)
+		} else if query != table.query {
+			t.Errorf("OrderColumn(%v) was incorrect, got: %s, want: %s.", table.sortKeys, query, table.query)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package sqlsource
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// CountError is returned, if a count query of a request failed.
+type CountError struct {
+	// The count query, which failed.
+	Kind QueryKind
+
+	Err error
+}
+
+func (e *CountError) Error() string {
+	return fmt.Sprintf("%s query failed: %s", e.Kind, e.Err)
+}
+
+// Unwrap returns the error, which caused the count query to fail.
+func (e *CountError) Unwrap() error {
+	return e.Err
+}
+
+// The outcome of a count query.
+type countResult struct {
+	count uint64
+	err   error
+}
+
+// Rows is the sql implementation of datasource.Rows, which is backed directly by sql.Rows.
+// Each row is converted to its type-safe representation via the DatabaseConnector while
+// iterating. Counting runs in parallel, and is aborted once the Rows are closed.
+type Rows struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	dbConnector DatabaseConnector
+
+	// The underlying rows, which might be nil if the result is known to be empty
+	rows *sql.Rows
+
+	// Tracks the query of the underlying rows, which finishes once the rows are closed
+	observation *queryObservation
+
+	types []*sql.ColumnType
+	names []string
+	raw   [][]byte
+	dest  []interface{}
+
+	current map[string]interface{}
+	err     error
+	closed  bool
+
+	// Keyset pagination of the underlying rows, if any. The sort values are selected
+	// as the last columns, and are not part of the returned rows.
+	keyset       *keyset
+	limit        uint64
+	rowCount     uint64
+	keysetValues []interface{}
+	dataColumns  int
+	continuation string
+
+	totalCountChannel  chan countResult
+	filterCountChannel chan countResult
+}
+
+// Prepares the scan targets for the underlying rows.
+func (r *Rows) init() error {
+	if r.rows == nil {
+		return nil
+	}
+
+	types, err := r.rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	r.types = types
+	r.names = make([]string, len(types))
+	r.raw = make([][]byte, len(types))
+	r.dest = make([]interface{}, len(types))
+
+	for i, columnType := range types {
+		r.names[i] = strings.Replace(columnType.Name(), ".", "_", -1)
+		r.dest[i] = &r.raw[i]
+	}
+
+	r.dataColumns = len(types)
+	if r.keyset != nil {
+		r.dataColumns -= len(r.keyset.columns)
+		r.keysetValues = make([]interface{}, len(r.keyset.columns))
+	}
+
+	return nil
+}
+
+// Next advances to the next row, and converts it into its type-safe representation.
+func (r *Rows) Next() bool {
+	if r.closed || r.err != nil || r.rows == nil {
+		return false
+	}
+
+	if !r.rows.Next() {
+		return false
+	}
+
+	if err := r.rows.Scan(r.dest...); err != nil {
+		r.err = err
+		return false
+	}
+
+	row := make(map[string]interface{}, r.dataColumns)
+	for i, item := range r.raw {
+		value, err := r.dbConnector.MakeItemTypeSafe(item, r.types[i])
+		if err != nil {
+			r.err = err
+			return false
+		}
+
+		if i < r.dataColumns {
+			row[r.names[i]] = value
+		} else {
+			r.keysetValues[i-r.dataColumns] = value
+		}
+	}
+
+	r.current = row
+	r.rowCount++
+	return true
+}
+
+// Row returns the current row.
+func (r *Rows) Row() map[string]interface{} {
+	return r.current
+}
+
+// Err returns the error which occurred during iteration, if any.
+func (r *Rows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if r.rows == nil {
+		return nil
+	}
+
+	return r.rows.Err()
+}
+
+// Continuation returns the continuation token for the page after the last iterated row. A
+// token is only issued if the page is full, as otherwise there can be no next page.
+func (r *Rows) Continuation() (string, error) {
+	if r.continuation != "" || r.keyset == nil || r.limit == 0 || r.rowCount < r.limit {
+		return r.continuation, nil
+	}
+
+	return encodeContinuation(r.keyset.orders, r.keysetValues)
+}
+
+// Counts waits for the parallel count queries to finish. If any of them failed, the error of
+// the first failed count is returned, which is a *CountError unless the counting was aborted.
+func (r *Rows) Counts() (uint64, uint64, error) {
+	total := waitForCount(r.ctx, r.totalCountChannel)
+
+	filtered := total
+	if r.filterCountChannel != nil {
+		filtered = waitForCount(r.ctx, r.filterCountChannel)
+	}
+
+	for _, result := range []countResult{total, filtered} {
+		if result.err != nil {
+			return 0, 0, result.err
+		}
+	}
+
+	return total.count, filtered.count, nil
+}
+
+// Close closes the underlying rows, and aborts all pending counts.
+func (r *Rows) Close() error {
+	if r.closed {
+		return nil
+	}
+
+	r.closed = true
+	defer r.cancel()
+
+	if r.rows == nil {
+		return nil
+	}
+
+	r.observation.finish(r.rowCount, r.Err())
+
+	return r.rows.Close()
+}
@@ -0,0 +1,70 @@
+package sqlsource
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRowsCounts(t *testing.T) {
+	queryErr := errors.New("failed")
+
+	tables := []struct {
+		name          string
+		total         countResult
+		filtered      *countResult
+		totalCount    uint64
+		filteredCount uint64
+		errKind       QueryKind
+	}{
+		{"unfiltered", countResult{count: 5}, nil, 5, 5, ""},
+		{"filtered", countResult{count: 5}, &countResult{count: 3}, 5, 3, ""},
+		{"failed total", countResult{err: &CountError{Kind: QueryTotalCount, Err: queryErr}}, &countResult{count: 3}, 0, 0, QueryTotalCount},
+		{"failed filtered", countResult{count: 5}, &countResult{err: &CountError{Kind: QueryFilteredCount, Err: queryErr}}, 0, 0, QueryFilteredCount},
+	}
+
+	for _, table := range tables {
+		rows := &Rows{
+			ctx:               context.Background(),
+			totalCountChannel: make(chan countResult, 1),
+		}
+
+		rows.totalCountChannel <- table.total
+		if table.filtered != nil {
+			rows.filterCountChannel = make(chan countResult, 1)
+			rows.filterCountChannel <- *table.filtered
+		}
+
+		totalCount, filteredCount, err := rows.Counts()
+		if totalCount != table.totalCount || filteredCount != table.filteredCount {
+			t.Errorf("Counts of %s were incorrect, got: %d/%d, want: %d/%d.", table.name, totalCount, filteredCount, table.totalCount, table.filteredCount)
+		}
+
+		if table.errKind == "" {
+			if err != nil {
+				t.Errorf("Counts of %s failed: %s", table.name, err)
+			}
+
+			continue
+		}
+
+		countErr, isCountErr := err.(*CountError)
+		if !isCountErr || countErr.Kind != table.errKind || countErr.Unwrap() != queryErr {
+			t.Errorf("Error of %s was incorrect, got: %v, want: %s query failed.", table.name, err, table.errKind)
+		}
+	}
+}
+
+func TestRowsCountsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rows := &Rows{
+		ctx:               ctx,
+		totalCountChannel: make(chan countResult, 1),
+	}
+
+	if _, _, err := rows.Counts(); err != context.Canceled {
+		t.Errorf("Error was incorrect, got: %v, want: %v.", err, context.Canceled)
+	}
+}
@@ -0,0 +1,122 @@
+package sqlsource
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"gopkg.in/birkirb/loggers.v1/log"
+)
+
+// A single in-flight execution, shared by all callers with the same key.
+type flight struct {
+	done    chan struct{}
+	value   interface{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightGroup coalesces concurrent executions with the same key, so that a single execution
+// serves all waiting callers.
+type flightGroup struct {
+	mutex   sync.Mutex
+	flights map[string]*flight
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{
+		flights: make(map[string]*flight),
+	}
+}
+
+// do executes fn, unless an execution with the same key is already in flight, in which case
+// its result is awaited instead. Each caller honours its own context, and stops waiting once
+// it is done. The execution itself runs with a context derived from the one of the caller who
+// started it, so its values (e.g. for tracing) and its deadline are retained. It is however
+// only cancelled once all callers stopped waiting, as it would not serve anyone anymore. A panic
+// of fn is returned as error to all callers. The returned bool reports, whether the result was
+// shared with an execution started by another caller.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, bool, error) {
+	g.mutex.Lock()
+
+	current, shared := g.flights[key]
+	if !shared {
+		flightCtx, cancel := detachContext(ctx)
+
+		current = &flight{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+
+		g.flights[key] = current
+		go g.run(flightCtx, key, current, fn)
+	}
+
+	current.waiters++
+	g.mutex.Unlock()
+
+	select {
+	case <-current.done:
+		g.leave(key, current)
+		return current.value, shared, current.err
+	case <-ctx.Done():
+		g.leave(key, current)
+		return nil, shared, ctx.Err()
+	}
+}
+
+// Derives the context of an execution from the context of the caller who started it. It keeps
+// the values and the deadline, but is not cancelled along with the caller.
+func detachContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		return context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	}
+
+	return context.WithCancel(context.WithoutCancel(ctx))
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, current *flight, fn func(ctx context.Context) (interface{}, error)) {
+	defer func() {
+		// The execution runs on a goroutine of its own, where no caller could recover a panic,
+		// so it would take down the whole process
+		if recovered := recover(); recovered != nil {
+			log.WithFields("panic", recovered, "stack", string(debug.Stack())).Error("Recovered panic of in-flight execution")
+			current.value, current.err = nil, fmt.Errorf("panic: %v", recovered)
+		}
+
+		g.mutex.Lock()
+		if g.flights[key] == current {
+			delete(g.flights, key)
+		}
+		g.mutex.Unlock()
+
+		current.cancel()
+		close(current.done)
+	}()
+
+	current.value, current.err = fn(ctx)
+}
+
+// Stops waiting for a flight. If it was the last caller waiting, the flight is cancelled, and
+// forgotten, so later callers start a new one.
+func (g *flightGroup) leave(key string, current *flight) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	current.waiters--
+	if current.waiters > 0 {
+		return
+	}
+
+	select {
+	case <-current.done:
+	default:
+		current.cancel()
+
+		if g.flights[key] == current {
+			delete(g.flights, key)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package sqlsource
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupSharesExecution(t *testing.T) {
+	group := newFlightGroup()
+
+	release := make(chan struct{})
+	executions := 0
+
+	fn := func(ctx context.Context) (interface{}, error) {
+		executions++
+		<-release
+		return "result", nil
+	}
+
+	// The first caller starts the flight, which blocks until released
+	var wait sync.WaitGroup
+	results := make([]interface{}, 3)
+	for i := range results {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+
+			value, _, err := group.do(context.Background(), "key", fn)
+			if err != nil {
+				t.Errorf("Flight failed: %s", err)
+			}
+
+			results[i] = value
+		}(i)
+	}
+
+	waitForWaiters(t, group, "key", 3)
+	close(release)
+	wait.Wait()
+
+	if executions != 1 {
+		t.Errorf("Executions were incorrect, got: %d, want: 1.", executions)
+	}
+
+	for _, result := range results {
+		if result != "result" {
+			t.Errorf("Result was incorrect, got: %v, want: result.", result)
+		}
+	}
+}
+
+func TestFlightGroupCancellation(t *testing.T) {
+	group := newFlightGroup()
+
+	cancelled := make(chan struct{})
+	fn := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+
+	errs := make(chan error, 2)
+	go func() {
+		_, _, err := group.do(firstCtx, "key", fn)
+		errs <- err
+	}()
+	go func() {
+		_, _, err := group.do(secondCtx, "key", fn)
+		errs <- err
+	}()
+
+	waitForWaiters(t, group, "key", 2)
+
+	// A single caller giving up must not cancel the shared execution
+	cancelFirst()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("Cancelled caller was incorrect, got: %v, want: %v.", err, context.Canceled)
+	}
+
+	select {
+	case <-cancelled:
+		t.Fatal("Execution was cancelled, while a caller was still waiting")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Once nobody waits anymore, the execution is cancelled
+	cancelSecond()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("Cancelled caller was incorrect, got: %v, want: %v.", err, context.Canceled)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Execution was not cancelled, after all callers gave up")
+	}
+}
+
+func TestFlightGroupPanic(t *testing.T) {
+	group := newFlightGroup()
+
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (interface{}, error) {
+		<-release
+		panic("broken query")
+	}
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, _, err := group.do(context.Background(), "key", fn)
+			errs <- err
+		}()
+	}
+
+	waitForWaiters(t, group, "key", 2)
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err == nil || !strings.Contains(err.Error(), "broken query") {
+			t.Errorf("Error of panicked execution was incorrect, got: %v, want: panic: broken query.", err)
+		}
+	}
+
+	if value, _, err := group.do(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+		return "result", nil
+	}); err != nil || value != "result" {
+		t.Errorf("Execution after panic was incorrect, got: %v (%v), want: result.", value, err)
+	}
+}
+
+type contextKey string
+
+func TestFlightGroupContext(t *testing.T) {
+	group := newFlightGroup()
+
+	deadline := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.WithValue(context.Background(), contextKey("trace"), "abc"), deadline)
+	defer cancel()
+
+	value, _, err := group.do(ctx, "key", func(ctx context.Context) (interface{}, error) {
+		flightDeadline, _ := ctx.Deadline()
+		return []interface{}{ctx.Value(contextKey("trace")), flightDeadline.Equal(deadline)}, nil
+	})
+	if err != nil {
+		t.Fatalf("Flight failed: %s", err)
+	}
+
+	if want := []interface{}{"abc", true}; !reflect.DeepEqual(value, want) {
+		t.Errorf("Context of the execution was incorrect, got value and deadline: %v, want: %v.", value, want)
+	}
+}
+
+// Waits until the given number of callers wait for the flight of the key.
+func waitForWaiters(t *testing.T, group *flightGroup, key string, waiters int) {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		group.mutex.Lock()
+		current, exists := group.flights[key]
+		done := exists && current.waiters == waiters
+		group.mutex.Unlock()
+
+		if done {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("Timed out waiting for %d waiters", waiters)
+}
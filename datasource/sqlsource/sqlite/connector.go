@@ -0,0 +1,170 @@
+// Package sqlite implements a sqlsource.DatabaseConnector for SQLite databases, which allows
+// to run the sql data source entirely locally. The package does not register a database driver
+// itself, so a driver registered as "sqlite3" (e.g. github.com/mattn/go-sqlite3) must be imported.
+package sqlite
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"gopkg.in/birkirb/loggers.v1/log"
+
+	"github.com/tableaux-project/tableaux/datasource/sqlsource"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource/util"
+)
+
+// DriverName is the name of the database driver, which is used to open databases.
+const DriverName = "sqlite3"
+
+// Selects the primary keys of all tables in order, as tableName, columnName.
+const primaryKeyQuery = `SELECT m.name, p.name
+FROM sqlite_master AS m JOIN pragma_table_info(m.name) AS p
+WHERE m.type = 'table' AND p.pk > 0
+ORDER BY m.name, p.pk`
+
+// Selects all foreign keys, as tableName, columnName, referencedTableName, referencedColumnName.
+// If a foreign key references the primary key implicitly, the referenced column is looked up.
+const foreignKeyQuery = `SELECT m.name, f."from", f."table",
+COALESCE(f."to", (SELECT p.name FROM pragma_table_info(f."table") AS p WHERE p.pk = f.seq + 1))
+FROM sqlite_master AS m JOIN pragma_foreign_key_list(m.name) AS f
+WHERE m.type = 'table'
+ORDER BY m.name, f.id, f.seq`
+
+// Selects all columns, as tableName, columnName, isNullable.
+const columnQuery = `SELECT m.name, p.name, CASE WHEN p."notnull" = 0 AND p.pk = 0 THEN 'YES' ELSE 'NO' END
+FROM sqlite_master AS m JOIN pragma_table_info(m.name) AS p
+WHERE m.type = 'table'`
+
+// DatabaseConnector is the SQLite implementation of a sqlsource.DatabaseConnector.
+type DatabaseConnector struct {
+	*sqlsource.CommonDatabaseConnector
+}
+
+// NewSQLiteDatabaseConnector opens the SQLite database with the given data source name (e.g. a file
+// path, or "file::memory:?cache=shared"), and reads its keys and relations.
+func NewSQLiteDatabaseConnector(dataSourceName string) (sqlsource.DatabaseConnector, error) {
+	db, err := sql.Open(DriverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := NewSQLiteDatabaseConnectorFromDB(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return connector, nil
+}
+
+// NewSQLiteDatabaseConnectorFromDB creates a DatabaseConnector for an already opened SQLite
+// database, and reads its keys and relations. Closing the connector closes the database.
+func NewSQLiteDatabaseConnectorFromDB(db *sql.DB) (sqlsource.DatabaseConnector, error) {
+	primaryKeyRows, err := db.Query(primaryKeyQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.LoggingRowsCloser(primaryKeyRows, "sqlite-primary-keys")
+
+	primaryKeyCache, err := sqlsource.ExtractCommonPrimaryKeyCache(primaryKeyRows)
+	if err != nil {
+		return nil, err
+	}
+
+	foreignKeyRows, err := db.Query(foreignKeyQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.LoggingRowsCloser(foreignKeyRows, "sqlite-foreign-keys")
+
+	joinForeignKeyCache, err := sqlsource.ExtractCommonJoinForeignKeyCache(foreignKeyRows)
+	if err != nil {
+		return nil, err
+	}
+
+	columnRows, err := db.Query(columnQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	defer util.LoggingRowsCloser(columnRows, "sqlite-columns")
+
+	columnCache, err := sqlsource.ExtractCommonColumnCache(columnRows)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key resolver maps the relation of two tables, instead of individual columns
+	foreignKeyCache := make(map[sqlsource.TableDoublet][]sqlsource.TableKeyDoublet)
+	for source, target := range joinForeignKeyCache {
+		key := sqlsource.TableDoublet{OriginName: source.Table, TargetName: target.Table}
+		foreignKeyCache[key] = append(foreignKeyCache[key], sqlsource.TableKeyDoublet{PrimaryKey: target.Column, ForeignKey: source.Column})
+	}
+
+	log.WithFields(
+		"tables", len(primaryKeyCache),
+		"relations", len(foreignKeyCache),
+	).Info("Successfully read SQLite schema")
+
+	return &DatabaseConnector{
+		CommonDatabaseConnector: sqlsource.NewCommonDatabaseConnector(
+			db,
+			sqlsource.NewCommonJoinResolver(columnCache, joinForeignKeyCache),
+			sqlsource.NewCommonKeyResolver(primaryKeyCache, foreignKeyCache),
+			QueryBuilder{},
+		),
+	}, nil
+}
+
+// DatabaseVersion returns the version of the SQLite library.
+func (connector DatabaseConnector) DatabaseVersion() (string, error) {
+	var version string
+	err := connector.DatabaseObject().QueryRow("SELECT sqlite_version()").Scan(&version)
+
+	return version, err
+}
+
+// MakeItemTypeSafe converts a raw item into its type-safe representation, based on the
+// affinity of the declared column type.
+func (connector DatabaseConnector) MakeItemTypeSafe(item []byte, itemType *sql.ColumnType) (interface{}, error) {
+	return makeItemTypeSafe(item, itemType.DatabaseTypeName())
+}
+
+// Converts a raw item by the affinity of the declared type, as determined by the rules of SQLite
+// (see https://www.sqlite.org/datatype3.html). Booleans and dates are recognized additionally.
+// Expressions (e.g. COUNT) have no declared type, so they are converted by their content.
+func makeItemTypeSafe(item []byte, databaseTypeName string) (interface{}, error) {
+	if item == nil {
+		return nil, nil
+	}
+
+	typeName := strings.ToUpper(databaseTypeName)
+
+	switch {
+	case strings.Contains(typeName, "BOOL"):
+		return string(item) == "1" || strings.ToLower(string(item)) == "true", nil
+	case strings.Contains(typeName, "INT"):
+		return strconv.ParseInt(string(item), 10, 64)
+	case strings.Contains(typeName, "CHAR"), strings.Contains(typeName, "CLOB"), strings.Contains(typeName, "TEXT"),
+		strings.Contains(typeName, "DATE"), strings.Contains(typeName, "TIME"):
+		return string(item), nil
+	case strings.Contains(typeName, "BLOB"):
+		return item, nil
+	case strings.Contains(typeName, "REAL"), strings.Contains(typeName, "FLOA"), strings.Contains(typeName, "DOUB"):
+		return strconv.ParseFloat(string(item), 64)
+	}
+
+	// Numeric affinity, or no declared type at all
+	if intValue, err := strconv.ParseInt(string(item), 10, 64); err == nil {
+		return intValue, nil
+	}
+
+	if floatValue, err := strconv.ParseFloat(string(item), 64); err == nil {
+		return floatValue, nil
+	}
+
+	return string(item), nil
+}
@@ -0,0 +1,507 @@
+//go:build sqlite
+// +build sqlite
+
+// The integration tests require the SQLite driver, and are only run with the "sqlite" build tag:
+//
+//	go test -tags sqlite ./datasource/sqlsource/sqlite/...
+//
+// which is what "make test-sqlite" (and "make check") does.
+
+package sqlite
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/tableaux-project/tableaux"
+	"github.com/tableaux-project/tableaux/config"
+	"github.com/tableaux-project/tableaux/datasource"
+	"github.com/tableaux-project/tableaux/datasource/sqlsource"
+)
+
+var fixtures = []string{
+	`CREATE TABLE organization (id INTEGER PRIMARY KEY, name TEXT NOT NULL)`,
+	`CREATE TABLE person (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		country TEXT,
+		organization_id INTEGER REFERENCES organization (id)
+	)`,
+	`INSERT INTO organization (id, name) VALUES (1, 'Acme'), (2, 'Initech')`,
+	`INSERT INTO person (id, name, country, organization_id) VALUES
+		(1, 'Alice', 'DE', 1),
+		(2, 'Bob', 'CH', 2),
+		(3, 'Carol', 'AT', 1),
+		(4, 'Dave', 'DE', NULL),
+		(5, 'O''Brien', 'DE', 2)`,
+}
+
+func newTestConnector(t *testing.T, observers ...sqlsource.Observer) datasource.Connector {
+	directory, err := ioutil.TempDir("", "tableaux-sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		os.RemoveAll(directory)
+	})
+
+	databaseConnector, err := NewSQLiteDatabaseConnector(filepath.Join(directory, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		databaseConnector.Close()
+	})
+
+	for _, fixture := range fixtures {
+		if _, err := databaseConnector.DatabaseObject().Exec(fixture); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The keys are read on creation, so the connector must be recreated after creating the tables
+	databaseConnector, err = NewSQLiteDatabaseConnectorFromDB(databaseConnector.DatabaseObject())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enumMapper, err := config.NewEnumMapperFromFolder(filepath.Join("testfiles", "enum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translator, err := config.NewTranslatorFromFolder(filepath.Join("testfiles", "i18n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaMapper, err := config.NewSchemaMapperFromFolder(filepath.Join("testfiles", "schema"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := make([]sqlsource.Option, len(observers))
+	for i, observer := range observers {
+		options[i] = sqlsource.WithObserver(observer)
+	}
+
+	connector, err := sqlsource.NewConnector(databaseConnector, enumMapper, translator, schemaMapper, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return connector
+}
+
+func TestFetchData(t *testing.T) {
+	connector := newTestConnector(t)
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id", "person_name", "person_organization_name").
+		Filters(datasource.NewSimpleFilterGroup("person_country", tableaux.FilterEquals, []interface{}{"DE", "AT"})).
+		Orders(datasource.NewOrder("person_organization_name", tableaux.OrderDesc, nil)).
+		Locale("en").
+		Build()
+
+	result, totalCount, filteredCount, _, err := connector.FetchData(request)
+	if err != nil {
+		t.Fatalf("FetchData failed: %s", err)
+	}
+
+	if totalCount != 5 || filteredCount != 4 {
+		t.Errorf("Counts were incorrect, got: %d/%d, want: 5/4.", totalCount, filteredCount)
+	}
+
+	want := datasource.Result{
+		{"person_id": int64(5), "person_name": "O'Brien", "person_organization_name": "Initech"},
+		{"person_id": int64(1), "person_name": "Alice", "person_organization_name": "Acme"},
+		{"person_id": int64(3), "person_name": "Carol", "person_organization_name": "Acme"},
+		{"person_id": int64(4), "person_name": "Dave", "person_organization_name": nil},
+	}
+
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("FetchData was incorrect, got: %v, want: %v.", *result, want)
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	connector := newTestConnector(t)
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id", "person_unknown").
+		Filters(
+			datasource.NewSimpleFilterGroup("person_id", tableaux.FilterEquals, []interface{}{true}),
+			datasource.NewSimpleFilterGroup("person_name", tableaux.FilterMode("MATCHES"), []interface{}{"A"}),
+			datasource.NewSimpleFilterGroup("person_id", tableaux.FilterStartsWith, []interface{}{"1"}),
+			datasource.NewSimpleFilterGroup("person_id", tableaux.FilterBetween, []interface{}{[]interface{}{1}}),
+			datasource.NewSimpleFilterGroup("person_country", tableaux.FilterEquals, []interface{}{"Atlantis"}),
+		).
+		Orders(datasource.NewOrder("person_unknown", tableaux.OrderAsc, nil)).
+		Locale("xx").
+		TimeZone("Mars/Olympus_Mons").
+		Build()
+
+	err := connector.ValidateRequest(request)
+
+	errs, isValidationErrors := err.(datasource.ValidationErrors)
+	if !isValidationErrors {
+		t.Fatalf("ValidateRequest returned untyped error: %v", err)
+	}
+
+	want := datasource.ValidationErrors{
+		{Kind: datasource.ValidationUnknownLocale, Code: datasource.CodeUnknownLocale, Message: "unknown locale xx"},
+		{Kind: datasource.ValidationUnknownTimeZone, Code: datasource.CodeUnknownTimeZone, Message: "unknown time zone Mars/Olympus_Mons"},
+		{Kind: datasource.ValidationUnknownColumn, Code: datasource.CodeUnknownColumn, Path: "person_unknown", Message: "unknown column person_unknown"},
+		{Kind: datasource.ValidationInvalidValue, Code: datasource.CodeInvalidFilterValue, Path: "person_id",
+			Message: "invalid value true on column person_id: cannot parse value true as number"},
+		{Kind: datasource.ValidationUnsupportedFilterMode, Code: datasource.CodeUnsupportedFilterMode, Path: "person_name",
+			Message: "unsupported filter mode MATCHES on column person_name, supported modes are EQUALS, NOT_EQUALS, GREATER, " +
+				"GREATER_EQUALS, LESSER, LESSER_EQUALS, IN, BETWEEN, IS_NULL, IS_NOT_NULL, CONTAINS, STARTS_WITH, ENDS_WITH"},
+		{Kind: datasource.ValidationUnsupportedFilterMode, Code: datasource.CodeUnsupportedFilterMode, Path: "person_id",
+			Message: "unsupported filter mode STARTS_WITH on column person_id, supported modes are EQUALS, NOT_EQUALS, GREATER, " +
+				"GREATER_EQUALS, LESSER, LESSER_EQUALS, IN, BETWEEN, IS_NULL, IS_NOT_NULL"},
+		{Kind: datasource.ValidationInvalidValue, Code: datasource.CodeInvalidFilterValue, Path: "person_id",
+			Message: "invalid value [1] on column person_id: filter mode BETWEEN requires a list of two values"},
+		{Kind: datasource.ValidationInvalidValue, Code: datasource.CodeInvalidFilterValue, Path: "person_country",
+			Message: "invalid value Atlantis on column person_country: Atlantis is neither a key nor a label of enum country"},
+		{Kind: datasource.ValidationUnknownColumn, Code: datasource.CodeUnknownOrderColumn, Path: "person_unknown",
+			Message: "unknown order column person_unknown"},
+	}
+
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("ValidateRequest was incorrect, got: %+v, want: %+v.", errs, want)
+	}
+}
+
+func TestFetchDataComparison(t *testing.T) {
+	connector := newTestConnector(t)
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(
+			datasource.NewSimpleFilterGroup("person_id", tableaux.FilterGreater, []interface{}{"2"}),
+			datasource.NewSimpleFilterGroup("person_id", tableaux.FilterLesserEquals, []interface{}{int64(4)}),
+		).
+		Orders(datasource.NewOrder("person_id", tableaux.OrderAsc, nil)).
+		Locale("en").
+		Build()
+
+	result, _, _, _, err := connector.FetchData(request)
+	if err != nil {
+		t.Fatalf("FetchData failed: %s", err)
+	}
+
+	want := datasource.Result{
+		{"person_id": int64(3)},
+		{"person_id": int64(4)},
+	}
+
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("FetchData was incorrect, got: %v, want: %v.", *result, want)
+	}
+}
+
+func TestFetchDataFilterModes(t *testing.T) {
+	connector := newTestConnector(t)
+
+	tables := []struct {
+		filterGroup datasource.FilterGroup
+		ids         []int64
+	}{
+		{datasource.NewSimpleFilterGroup("person_id", tableaux.FilterIn, []interface{}{[]interface{}{int64(1), int64(3)}}), []int64{1, 3}},
+		{datasource.NewSimpleFilterGroup("person_id", tableaux.FilterBetween, []interface{}{[]interface{}{"2", "4"}}), []int64{2, 3, 4}},
+		{datasource.NewSimpleFilterGroup("person_organization_name", tableaux.FilterIsNull, []interface{}{nil}), []int64{4}},
+		{datasource.NewSimpleFilterGroup("person_organization_name", tableaux.FilterIsNotNull, []interface{}{nil}), []int64{1, 2, 3, 5}},
+		{datasource.NewSimpleFilterGroup("person_name", tableaux.FilterContains, []interface{}{"ro"}), []int64{3}},
+		{datasource.NewSimpleFilterGroup("person_name", tableaux.FilterStartsWith, []interface{}{"O'"}), []int64{5}},
+		{datasource.NewSimpleFilterGroup("person_name", tableaux.FilterEndsWith, []interface{}{"e"}), []int64{1, 4}},
+		// Wildcards are matched literally
+		{datasource.NewSimpleFilterGroup("person_name", tableaux.FilterContains, []interface{}{"_"}), nil},
+	}
+
+	for _, table := range tables {
+		request := datasource.NewRequestBuilder("persons").
+			Columns("person_id").
+			Filters(table.filterGroup).
+			Orders(datasource.NewOrder("person_id", tableaux.OrderAsc, nil)).
+			Locale("en").
+			Build()
+
+		if err := connector.ValidateRequest(request); err != nil {
+			t.Errorf("ValidateRequest(%v) failed: %s", table.filterGroup.Filters(), err)
+			continue
+		}
+
+		result, _, _, _, err := connector.FetchData(request)
+		if err != nil {
+			t.Errorf("FetchData(%v) failed: %s", table.filterGroup.Filters(), err)
+			continue
+		}
+
+		var ids []int64
+		for _, row := range *result {
+			ids = append(ids, row["person_id"].(int64))
+		}
+
+		if !reflect.DeepEqual(ids, table.ids) {
+			t.Errorf("FetchData(%v) was incorrect, got: %v, want: %v.", table.filterGroup.Filters(), ids, table.ids)
+		}
+	}
+}
+
+func TestFetchDataEnumLabel(t *testing.T) {
+	connector := newTestConnector(t)
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(datasource.NewSimpleFilterGroup("person_country", tableaux.FilterNotEquals, []interface{}{"germany"})).
+		Orders(datasource.NewOrder("person_id", tableaux.OrderAsc, nil)).
+		Locale("en").
+		Build()
+
+	if err := connector.ValidateRequest(request); err != nil {
+		t.Fatalf("ValidateRequest failed: %s", err)
+	}
+
+	result, _, _, _, err := connector.FetchData(request)
+	if err != nil {
+		t.Fatalf("FetchData failed: %s", err)
+	}
+
+	want := datasource.Result{
+		{"person_id": int64(2)},
+		{"person_id": int64(3)},
+	}
+
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("FetchData was incorrect, got: %v, want: %v.", *result, want)
+	}
+}
+
+func TestFetchDataContinuation(t *testing.T) {
+	connector := newTestConnector(t)
+
+	builder := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Orders(datasource.NewOrder("person_name", tableaux.OrderDesc, nil)).
+		Limit(2).
+		Locale("en")
+
+	var ids []int64
+	continuation := ""
+	for {
+		result, _, _, next, err := connector.FetchData(builder.Continuation(continuation).Build())
+		if err != nil {
+			t.Fatalf("FetchData failed: %s", err)
+		}
+
+		for _, row := range *result {
+			ids = append(ids, row["person_id"].(int64))
+		}
+
+		if next == "" {
+			break
+		}
+		continuation = next
+	}
+
+	if want := []int64{5, 4, 3, 2, 1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Pages were incorrect, got: %v, want: %v.", ids, want)
+	}
+}
+
+func TestFetchDataContinuationNulls(t *testing.T) {
+	connector := newTestConnector(t)
+
+	// Dave has no organization, and SQLite orders NULLs first
+	tables := []struct {
+		direction tableaux.Order
+		limit     uint64
+		ids       []int64
+	}{
+		{tableaux.OrderAsc, 1, []int64{4, 1, 3, 2, 5}},
+		{tableaux.OrderAsc, 2, []int64{4, 1, 3, 2, 5}},
+		{tableaux.OrderDesc, 1, []int64{2, 5, 1, 3, 4}},
+		{tableaux.OrderDesc, 2, []int64{2, 5, 1, 3, 4}},
+	}
+
+	for _, table := range tables {
+		builder := datasource.NewRequestBuilder("persons").
+			Columns("person_id").
+			Orders(datasource.NewOrder("person_organization_name", table.direction, nil)).
+			Limit(table.limit).
+			Locale("en")
+
+		var ids []int64
+		continuation := ""
+		for pages := 0; pages < 10; pages++ {
+			result, _, _, next, err := connector.FetchData(builder.Continuation(continuation).Build())
+			if err != nil {
+				t.Fatalf("FetchData failed: %s", err)
+			}
+
+			for _, row := range *result {
+				ids = append(ids, row["person_id"].(int64))
+			}
+
+			if next == "" {
+				break
+			}
+			continuation = next
+		}
+
+		if !reflect.DeepEqual(ids, table.ids) {
+			t.Errorf("Pages of %s by %d were incorrect, got: %v, want: %v.", table.direction, table.limit, ids, table.ids)
+		}
+	}
+}
+
+func TestFetchFacets(t *testing.T) {
+	connector := newTestConnector(t)
+
+	request := datasource.NewRequestBuilder("persons").Columns("person_id").Locale("en").Build()
+
+	result, err := connector.FetchFacets(context.Background(),
+		datasource.NewFacetRequest(request, "person_country", tableaux.FacetOrderCount, tableaux.OrderDesc, 0))
+	if err != nil {
+		t.Fatalf("FetchFacets failed: %s", err)
+	}
+
+	want := datasource.FacetResult{
+		{Value: "DE", Count: 3, TranslationKey: "enum.country.de"},
+		{Value: "AT", Count: 1, TranslationKey: "enum.country.at"},
+		{Value: "CH", Count: 1, TranslationKey: "enum.country.ch"},
+	}
+
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("Facets were incorrect, got: %+v, want: %+v.", *result, want)
+	}
+}
+
+func TestFetchAggregation(t *testing.T) {
+	connector := newTestConnector(t)
+
+	request := datasource.NewAggregationRequestBuilder("persons").
+		GroupBy("person_organization_name").
+		Aggregations(
+			datasource.NewAggregation(tableaux.AggregateCount, ""),
+			datasource.NewAggregation(tableaux.AggregateMax, "person_name"),
+		).
+		Orders(datasource.NewOrder("count", tableaux.OrderDesc, nil), datasource.NewOrder("person_organization_name", tableaux.OrderAsc, nil)).
+		Locale("en").
+		Build()
+
+	aggregator := connector.(datasource.Aggregator)
+	if err := aggregator.ValidateAggregation(context.Background(), request); err != nil {
+		t.Fatalf("ValidateAggregation failed: %s", err)
+	}
+
+	result, err := aggregator.FetchAggregation(context.Background(), request)
+	if err != nil {
+		t.Fatalf("FetchAggregation failed: %s", err)
+	}
+
+	want := datasource.AggregationResult{
+		{Groups: map[string]interface{}{"person_organization_name": "Acme"}, Values: map[string]interface{}{"count": uint64(2), "max_person_name": "Carol"}},
+		{Groups: map[string]interface{}{"person_organization_name": "Initech"}, Values: map[string]interface{}{"count": uint64(2), "max_person_name": "O'Brien"}},
+		{Groups: map[string]interface{}{"person_organization_name": nil}, Values: map[string]interface{}{"count": uint64(1), "max_person_name": "Dave"}},
+	}
+
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("Aggregation was incorrect, got: %+v, want: %+v.", *result, want)
+	}
+}
+
+type traceKey struct{}
+
+func TestObserver(t *testing.T) {
+	var mutex sync.Mutex
+	rows := make(map[sqlsource.QueryKind]uint64)
+
+	connector := newTestConnector(t, sqlsource.ObserverFunc(func(ctx context.Context, event sqlsource.QueryEvent) {
+		if event.Err != nil || event.Entity != "person" || event.Query == "" {
+			t.Errorf("Event was incorrect, got: %+v.", event)
+		}
+
+		if trace := ctx.Value(traceKey{}); trace != "abc" {
+			t.Errorf("Context of %s query was incorrect, got trace: %v, want: abc.", event.Kind, trace)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		rows[event.Kind] = event.Rows
+	}))
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id").
+		Filters(datasource.NewSimpleFilterGroup("person_country", tableaux.FilterEquals, []interface{}{"DE"})).
+		Locale("en").
+		Build()
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "abc")
+	if _, _, _, _, err := connector.FetchDataContext(ctx, request); err != nil {
+		t.Fatalf("FetchDataContext failed: %s", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	want := map[sqlsource.QueryKind]uint64{
+		sqlsource.QueryData:          3,
+		sqlsource.QueryTotalCount:    5,
+		sqlsource.QueryFilteredCount: 3,
+	}
+
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Observed queries were incorrect, got: %v, want: %v.", rows, want)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	connector := newTestConnector(t).(*sqlsource.Connector)
+
+	request := datasource.NewRequestBuilder("persons").
+		Columns("person_id", "person_name").
+		Filters(datasource.NewSimpleFilterGroup("person_country", tableaux.FilterEquals, []interface{}{"DE"})).
+		Locale("en").
+		Limit(2).
+		Build()
+
+	explanation, err := connector.Explain(context.Background(), request, true)
+	if err != nil {
+		t.Fatalf("Explain failed: %s", err)
+	}
+
+	if explanation.DeferredLoading {
+		t.Error("Deferred loading was chosen for a request without joins")
+	}
+
+	kinds := make([]sqlsource.QueryKind, len(explanation.Queries))
+	for i, query := range explanation.Queries {
+		kinds[i] = query.Kind
+
+		if query.Query == "" || len(query.Plan) == 0 {
+			t.Errorf("Explained query was incorrect, got: %+v.", query)
+		}
+	}
+
+	wantKinds := []sqlsource.QueryKind{sqlsource.QueryTotalCount, sqlsource.QueryFilteredCount, sqlsource.QueryData}
+	if !reflect.DeepEqual(kinds, wantKinds) {
+		t.Errorf("Explained queries were incorrect, got: %v, want: %v.", kinds, wantKinds)
+	}
+
+	wantArguments := []interface{}{"DE", uint64(2)}
+	if arguments := explanation.Queries[2].Arguments; !reflect.DeepEqual(arguments, wantArguments) {
+		t.Errorf("Arguments of the data query were incorrect, got: %v, want: %v.", arguments, wantArguments)
+	}
+}
@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tableaux-project/tableaux/datasource/sqlsource"
+)
+
+// QueryBuilder is the SQLite implementation of a sqlsource.QueryBuilder.
+type QueryBuilder struct {
+	sqlsource.CommonQueryBuilder
+}
+
+// IfNull returns the query, or the given value if the query results in NULL.
+func (builder QueryBuilder) IfNull(query string, then interface{}) string {
+	if stringValue, isString := then.(string); isString {
+		return fmt.Sprintf("IFNULL(%s, '%s')", query, strings.Replace(stringValue, "'", "''", -1))
+	}
+
+	return fmt.Sprintf("IFNULL(%s, %v)", query, then)
+}
+
+// SelectWithLimitQuery turns the query into a select with LIMIT and OFFSET clauses.
+func (builder QueryBuilder) SelectWithLimitQuery(query string, limitPlaceholder, offsetPlaceholder string) string {
+	if offsetPlaceholder == "" {
+		return "SELECT " + query + " LIMIT " + limitPlaceholder
+	}
+
+	return "SELECT " + query + " LIMIT " + limitPlaceholder + " OFFSET " + offsetPlaceholder
+}
+
+// ExplainQuery turns the query into an EXPLAIN QUERY PLAN, as the plain EXPLAIN of SQLite
+// returns the bytecode of the query instead.
+func (builder QueryBuilder) ExplainQuery(query string) string {
+	return "EXPLAIN QUERY PLAN " + query
+}
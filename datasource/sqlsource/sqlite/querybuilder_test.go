@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMakeItemTypeSafe(t *testing.T) {
+	tables := []struct {
+		item             []byte
+		databaseTypeName string
+		value            interface{}
+	}{
+		{nil, "INTEGER", nil},
+		{[]byte("42"), "INTEGER", int64(42)},
+		{[]byte("42"), "BIGINT", int64(42)},
+		{[]byte("42"), "VARCHAR(255)", "42"},
+		{[]byte("1"), "BOOLEAN", true},
+		{[]byte("0"), "BOOLEAN", false},
+		{[]byte("1.5"), "REAL", 1.5},
+		{[]byte("2018-01-01"), "DATE", "2018-01-01"},
+		{[]byte{0, 1}, "BLOB", []byte{0, 1}},
+		{[]byte("7"), "", int64(7)},
+		{[]byte("2.5"), "NUMERIC", 2.5},
+		{[]byte("text"), "", "text"},
+	}
+
+	for _, table := range tables {
+		value, err := makeItemTypeSafe(table.item, table.databaseTypeName)
+		if err != nil {
+			t.Errorf("makeItemTypeSafe(%s, %s) failed: %s", table.item, table.databaseTypeName, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(value, table.value) {
+			t.Errorf("makeItemTypeSafe(%s, %s) was incorrect, got: %#v, want: %#v.", table.item, table.databaseTypeName, value, table.value)
+		}
+	}
+}
+
+func TestSelectWithLimitQuery(t *testing.T) {
+	builder := QueryBuilder{}
+
+	if query := builder.SelectWithLimitQuery("a FROM b", "?", ""); query != "SELECT a FROM b LIMIT ?" {
+		t.Errorf("SelectWithLimitQuery without offset was incorrect, got: %s.", query)
+	}
+
+	if query := builder.SelectWithLimitQuery("a FROM b", "?", "?"); query != "SELECT a FROM b LIMIT ? OFFSET ?" {
+		t.Errorf("SelectWithLimitQuery with offset was incorrect, got: %s.", query)
+	}
+
+	if query := builder.IfNull("a.name", "O'Brien"); query != "IFNULL(a.name, 'O''Brien')" {
+		t.Errorf("IfNull was incorrect, got: %s.", query)
+	}
+}
+
+func TestExplainQuery(t *testing.T) {
+	if query := (QueryBuilder{}).ExplainQuery("SELECT a FROM b"); query != "EXPLAIN QUERY PLAN SELECT a FROM b" {
+		t.Errorf("ExplainQuery was incorrect, got: %s.", query)
+	}
+}
@@ -1,7 +1,7 @@
 package util
 
 import (
-	"database/sql"
+	"io"
 	"regexp"
 	"strings"
 
@@ -22,8 +22,9 @@ func IdentifierToDescriptor(database string) string {
 }
 
 // LoggingRowsCloser is a helper method which wraps row closing
-// with a logging statement, if an error ocurs.
-func LoggingRowsCloser(rows *sql.Rows, usage string) {
+// with a logging statement, if an error ocurs. It accepts both
+// sql.Rows and datasource.Rows.
+func LoggingRowsCloser(rows io.Closer, usage string) {
 	if err := rows.Close(); err != nil {
 		log.WithFields(
 			"error", err,
@@ -0,0 +1,131 @@
+package datasource
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationKind categorizes the problems found while validating a request.
+type ValidationKind string
+
+const (
+	// ValidationInvalidRequest is a problem with the request as a whole, e.g. no selected columns.
+	ValidationInvalidRequest ValidationKind = "invalidRequest"
+
+	// ValidationUnknownSchema is a request against a schema, which does not exist.
+	ValidationUnknownSchema ValidationKind = "unknownSchema"
+
+	// ValidationUnknownLocale is a request for a locale, which has no translations.
+	ValidationUnknownLocale ValidationKind = "unknownLocale"
+
+	// ValidationUnknownTimeZone is a request for a time zone, which is not known to the system.
+	ValidationUnknownTimeZone ValidationKind = "unknownTimeZone"
+
+	// ValidationUnknownColumn is a path, which is not a column of the schema.
+	ValidationUnknownColumn ValidationKind = "unknownColumn"
+
+	// ValidationUnknownFilter is a column, of which the filter is not known to the Connector.
+	ValidationUnknownFilter ValidationKind = "unknownFilter"
+
+	// ValidationUnknownOrder is a column, of which the order is not known to the Connector.
+	ValidationUnknownOrder ValidationKind = "unknownOrder"
+
+	// ValidationUnknownResolver is a column, of which the path resolver is not known to the Connector.
+	ValidationUnknownResolver ValidationKind = "unknownResolver"
+
+	// ValidationUnsupportedFilterMode is a filter, of which the mode is not supported by the
+	// filter of its column.
+	ValidationUnsupportedFilterMode ValidationKind = "unsupportedFilterMode"
+
+	// ValidationInvalidValue is a value, which does not fit the type of its column.
+	ValidationInvalidValue ValidationKind = "invalidValue"
+
+	// ValidationUnsupportedAggregate is an aggregate function, which cannot be applied to its column.
+	ValidationUnsupportedAggregate ValidationKind = "unsupportedAggregate"
+
+	// ValidationUnknownBackend is a schema, which is not served by any backend known to a
+	// federated Connector.
+	ValidationUnknownBackend ValidationKind = "unknownBackend"
+
+	// ValidationUnsupportedJoin is a path, which is joined from a schema served by another backend.
+	ValidationUnsupportedJoin ValidationKind = "unsupportedJoin"
+)
+
+// ValidationCode identifies the check of a request, which failed. While the ValidationKind
+// categorizes a problem, the code tells where in the request it was found.
+type ValidationCode string
+
+const (
+	CodeNoColumns               ValidationCode = "noColumns"
+	CodeNoAggregations          ValidationCode = "noAggregations"
+	CodeUnknownSchema           ValidationCode = "unknownSchema"
+	CodeNoData                  ValidationCode = "noData"
+	CodeUnknownLocale           ValidationCode = "unknownLocale"
+	CodeUnknownTimeZone         ValidationCode = "unknownTimeZone"
+	CodeUnknownColumn           ValidationCode = "unknownColumn"
+	CodeUnknownFilterColumn     ValidationCode = "unknownFilterColumn"
+	CodeUnknownOrderColumn      ValidationCode = "unknownOrderColumn"
+	CodeUnknownGroupColumn      ValidationCode = "unknownGroupColumn"
+	CodeUnknownAggregateColumn  ValidationCode = "unknownAggregateColumn"
+	CodeUnknownFilter           ValidationCode = "unknownFilter"
+	CodeUnknownOrder            ValidationCode = "unknownOrder"
+	CodeUnknownPathResolver     ValidationCode = "unknownPathResolver"
+	CodeUnsupportedFilterMode   ValidationCode = "unsupportedFilterMode"
+	CodeInvalidFilterValue      ValidationCode = "invalidFilterValue"
+	CodeAggregateRequiresColumn ValidationCode = "aggregateRequiresColumn"
+	CodeUnsupportedAggregate    ValidationCode = "unsupportedAggregate"
+	CodeDuplicateAggregate      ValidationCode = "duplicateAggregate"
+	CodeUnknownBackend          ValidationCode = "unknownBackend"
+	CodeNoBackend               ValidationCode = "noBackend"
+	CodeCrossBackendPath        ValidationCode = "crossBackendPath"
+	CodeAggregationsUnsupported ValidationCode = "aggregationsUnsupported"
+)
+
+// ValidationError is a single problem of a request, as found by validating it. It is encoded
+// to JSON with stable names, so it can be reported to clients as is.
+type ValidationError struct {
+	Kind ValidationKind `json:"kind"`
+	Code ValidationCode `json:"code"`
+
+	// The offending path of the request, if the problem is bound to one.
+	Path string `json:"path,omitempty"`
+
+	// A human readable description of the problem.
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+// ValidationErrors collects all problems of a request, which were found in a single pass of
+// validating it. As an error, it is never empty.
+type ValidationErrors []ValidationError
+
+// Add appends a problem, of which the message is formatted according to the format specifier.
+func (errs *ValidationErrors) Add(kind ValidationKind, code ValidationCode, path, format string, a ...interface{}) {
+	*errs = append(*errs, ValidationError{
+		Kind:    kind,
+		Code:    code,
+		Path:    path,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// Err returns the collected problems as error, or nil if there are none.
+func (errs ValidationErrors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
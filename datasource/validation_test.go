@@ -0,0 +1,69 @@
+package datasource
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/tableaux-project/tableaux"
+)
+
+func TestValidationErrors(t *testing.T) {
+	var errs ValidationErrors
+	if err := errs.Err(); err != nil {
+		t.Errorf("Err without problems was incorrect, got: %v, want: nil.", err)
+	}
+
+	errs.Add(ValidationUnknownColumn, CodeUnknownColumn, "person_unknown", "unknown column %s", "person_unknown")
+	errs.Add(ValidationUnknownLocale, CodeUnknownLocale, "", "unknown locale %s", "xx")
+
+	err := errs.Err()
+	if err == nil || err.Error() != "unknown column person_unknown; unknown locale xx" {
+		t.Errorf("Err was incorrect, got: %v.", err)
+	}
+
+	want := ValidationError{Kind: ValidationUnknownColumn, Code: CodeUnknownColumn, Path: "person_unknown", Message: "unknown column person_unknown"}
+	if collected := err.(ValidationErrors); collected[0] != want {
+		t.Errorf("First problem was incorrect, got: %+v, want: %+v.", collected[0], want)
+	}
+}
+
+func TestValidationErrorJSON(t *testing.T) {
+	errs := ValidationErrors{
+		{Kind: ValidationUnknownColumn, Code: CodeUnknownColumn, Path: "person_unknown", Message: "unknown column person_unknown"},
+		{Kind: ValidationUnknownLocale, Code: CodeUnknownLocale, Message: "unknown locale xx"},
+	}
+
+	encoded, err := json.Marshal(errs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[{"kind":"unknownColumn","code":"unknownColumn","path":"person_unknown","message":"unknown column person_unknown"},` +
+		`{"kind":"unknownLocale","code":"unknownLocale","message":"unknown locale xx"}]`
+	if string(encoded) != want {
+		t.Errorf("JSON was incorrect, got: %s, want: %s.", encoded, want)
+	}
+}
+
+func TestFilterGroupsOf(t *testing.T) {
+	name := NewSimpleFilterGroup("person_name", tableaux.FilterEquals, []interface{}{"Alice"})
+	status := NewSimpleFilterGroup("person_status", tableaux.FilterEquals, []interface{}{"OPEN"})
+	assignee := NewSimpleFilterGroup("person_assignee", tableaux.FilterEquals, []interface{}{"me"})
+
+	expression := NewAndExpression(name, NewOrExpression(status, NewNotExpression(assignee)))
+
+	var paths []string
+	for _, filterGroup := range FilterGroupsOf(expression) {
+		paths = append(paths, filterGroup.Path())
+	}
+
+	want := []string{"person_name", "person_status", "person_assignee"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("FilterGroupsOf was incorrect, got: %v, want: %v.", paths, want)
+	}
+
+	if filterGroups := FilterGroupsOf(nil); filterGroups != nil {
+		t.Errorf("FilterGroupsOf(nil) was incorrect, got: %v, want: nil.", filterGroups)
+	}
+}